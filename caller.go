@@ -0,0 +1,142 @@
+package rainbowlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// CallerFormatFull prints the full path of the caller file, e.g. "/home/user/app/service/user.go:42".
+	CallerFormatFull = "full"
+	// CallerFormatShort prints the file name of the caller only, e.g. "user.go:42".
+	CallerFormatShort = "short"
+	// CallerFormatPackage prints the import path of the package and the file name of the caller,
+	// with the path of the main module trimmed, e.g. "service/user.go:42" for the file of
+	// the package "github.com/user/app/service" in the main module "github.com/user/app".
+	CallerFormatPackage = "package"
+)
+
+var (
+	// FullCallerMarshalFunc marshals the caller with the full path of the file.
+	FullCallerMarshalFunc CallerMarshalFunc = func(file string, line int) string {
+		return file + ":" + strconv.Itoa(line)
+	}
+
+	// ShortCallerMarshalFunc marshals the caller with the file name only.
+	ShortCallerMarshalFunc CallerMarshalFunc = func(file string, line int) string {
+		return filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+
+	// PackageCallerMarshalFunc marshals the caller with the import path of the package and the file name,
+	// with the path of the main module trimmed.
+	// The package of the file is known once a record of the logger is created in the file,
+	// otherwise the directory name and the file name are used.
+	PackageCallerMarshalFunc CallerMarshalFunc = func(file string, line int) string {
+		if pkgFile, ok := packageFiles.Load(file); ok {
+			return pkgFile.(string) + ":" + strconv.Itoa(line)
+		}
+		// runtime always reports file paths with forward slashes
+		if i := strings.LastIndexByte(file, '/'); i > 0 {
+			if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+				file = file[j+1:]
+			}
+		}
+		return file + ":" + strconv.Itoa(line)
+	}
+)
+
+// ParseCallerFormat returns the CallerMarshalFunc of the caller format, which is case-insensitive.
+func ParseCallerFormat(format string) (CallerMarshalFunc, error) {
+	switch strings.ToLower(format) {
+	case CallerFormatFull:
+		return FullCallerMarshalFunc, nil
+	case CallerFormatShort:
+		return ShortCallerMarshalFunc, nil
+	case CallerFormatPackage:
+		return PackageCallerMarshalFunc, nil
+	default:
+		return nil, fmt.Errorf("unknown caller format %q, '%s', '%s' or '%s' expected",
+			format, CallerFormatFull, CallerFormatShort, CallerFormatPackage)
+	}
+}
+
+// callerFrame is the result of symbolizing the program counter of a caller.
+type callerFrame struct {
+	file     string
+	line     int
+	function string
+}
+
+var (
+	// callerFrames caches frames of callers by the program counter, uintptr -> *callerFrame,
+	// so that each call site is symbolized once.
+	callerFrames sync.Map
+	// packageFiles caches the import path of the package joined with the file name by the file path,
+	// with the path of the main module trimmed, string -> string.
+	packageFiles sync.Map
+
+	// mainPackagePath and mainModulePath are the import path of the main package and the path of the main module.
+	mainPackagePath, mainModulePath = func() (string, string) {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			return bi.Path, bi.Main.Path
+		}
+		return "", ""
+	}()
+)
+
+// callerFrameOf returns the frame of the caller skip frames above the caller of callerFrameOf,
+// same as runtime.Caller(skip) called by the caller of callerFrameOf.
+func callerFrameOf(skip int) (*callerFrame, bool) {
+	var pcs [1]uintptr
+	// skip runtime.Callers and callerFrameOf itself
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return nil, false
+	}
+	if v, ok := callerFrames.Load(pcs[0]); ok {
+		return v.(*callerFrame), true
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if frame.File == "" {
+		return nil, false
+	}
+	cf := &callerFrame{file: frame.File, line: frame.Line, function: frame.Function}
+	if _, ok := packageFiles.Load(cf.file); !ok {
+		packageFiles.Store(cf.file, packageFile(cf.function, cf.file))
+	}
+	callerFrames.Store(pcs[0], cf)
+	return cf, true
+}
+
+// packageFile joins the import path of the package of the function and the file name,
+// and trims the path of the main module, e.g. "service/user.go" for the function
+// "github.com/user/app/service.(*User).Login" in the main module "github.com/user/app".
+func packageFile(function, file string) string {
+	pkg := function
+	// type arguments of generic functions may contain import paths
+	if i := strings.IndexByte(pkg, '['); i >= 0 {
+		pkg = pkg[:i]
+	}
+	slash := strings.LastIndexByte(pkg, '/')
+	if i := strings.IndexByte(pkg[slash+1:], '.'); i >= 0 {
+		pkg = pkg[:slash+1+i]
+	}
+	if pkg == "main" {
+		pkg = mainPackagePath
+	}
+	name := filepath.Base(file)
+	if pkg == "" {
+		return name
+	}
+	if mainModulePath != "" {
+		if pkg == mainModulePath {
+			return name
+		}
+		pkg = strings.TrimPrefix(pkg, mainModulePath+"/")
+	}
+	return pkg + "/" + name
+}
@@ -0,0 +1,73 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCallerFormat(t *testing.T) {
+	const file = "/home/user/app/service/user.go"
+	for format, expected := range map[string]string{
+		"full":    "/home/user/app/service/user.go:42",
+		"Short":   "user.go:42",
+		"PACKAGE": "service/user.go:42",
+	} {
+		f, err := ParseCallerFormat(format)
+		require.NoError(t, err)
+		assert.Equal(t, expected, f(file, 42))
+	}
+	assert.Equal(t, "user.go:42", PackageCallerMarshalFunc("user.go", 42))
+
+	_, err := ParseCallerFormat("relative")
+	assert.Error(t, err)
+}
+
+func TestParseColor(t *testing.T) {
+	for name, expected := range map[string]int{
+		"red": ColorRed, "hlBlue": ColorHlBlue, "BgGreen": ColorBgGreen, "bold": ColorBold, "hlbgpale": ColorHlBgPale, "38": 38,
+	} {
+		c, err := ParseColor(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, c, name)
+	}
+	_, err := ParseColor("rainbow")
+	assert.Error(t, err)
+}
+
+func TestPackageFile(t *testing.T) {
+	modulePath, packagePath := mainModulePath, mainPackagePath
+	defer func() { mainModulePath, mainPackagePath = modulePath, packagePath }()
+	mainModulePath, mainPackagePath = "github.com/user/app", "github.com/user/app/cmd/server"
+
+	for function, expected := range map[string]string{
+		"github.com/user/app/service.(*User).Login":               "service/user.go",
+		"github.com/user/app.Run":                                 "user.go",
+		"github.com/user/app/service.Map[github.com/other/lib.T]": "service/user.go",
+		"github.com/other/lib/store.Get.func1":                    "github.com/other/lib/store/user.go",
+		"main.main":                                               "cmd/server/user.go",
+	} {
+		assert.Equal(t, expected, packageFile(function, "/home/user/app/service/user.go"), function)
+	}
+}
+
+func TestCallerMetaKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(
+		WithMetaKeys(MetaCallerFieldName, MetaFuncFieldName),
+		WithCallerMarshalFunc(PackageCallerMarshalFunc),
+		AppendsEncoderWriters(JsonEnc, buf),
+	)
+
+	for i := 0; i < 2; i++ {
+		buf.Reset()
+		logger.Info().Msg("hello").Done()
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Regexp(t, `^caller_test\.go:\d+$`, record[MetaCallerFieldName])
+		assert.Equal(t, "github.com/rambollwong/rainbowlog.TestCallerMetaKeys", record[MetaFuncFieldName])
+	}
+}
@@ -5,22 +5,70 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/rambollwong/rainbowcat/writer/filewriter"
 	"github.com/spf13/viper"
 )
 
+// LoggerConfig is the configuration of a Logger.
+//
+// MetaKeys lists the meta fields printed at the front of each record in order,
+// by the names time, level, label, caller, func (function name of the caller) and message.
+// StackLevel is the minimum level of records having the stack of the goroutine added, empty to disable.
+// ConsoleColors sets the colors of console printing by key, keys are the names of MetaKeys, error, stack,
+// keys (names of fields) and end (the end of meta fields), or "level" / "line" followed by a level name for the level field and
+// the beginning of lines of the level, e.g. levelInfo or lineError. Colors are names of the Color constants
+// without the "Color" prefix in any case, e.g. red, hlBlue, bgGreen or bold.
+// CallerFormat is one of full, short (file name only) and package (import path of the package
+// relative to the main module and file name),
+// DurationFormat is float or int (the integer part only).
+//
+// Empty TimeFormat, MetaKeys, FieldNames, CallerFormat and DurationFormat keep the current settings of the logger,
+// e.g. the ones set by the global variables or options applied before, so they are empty in DefaultLoggerConfig.
 type LoggerConfig struct {
-	Enable                bool                        `mapstructure:"enable" json:"enable" yaml:"enable"`
-	Level                 string                      `mapstructure:"level" json:"level" yaml:"level"`
-	Label                 string                      `mapstructure:"label" json:"label" yaml:"label"`
-	Stack                 bool                        `mapstructure:"stack" json:"stack" yaml:"stack"`
-	EnableConsolePrinting bool                        `mapstructure:"enableConsolePrinting" json:"enableConsolePrinting" yaml:"enableConsolePrinting"`
-	EnableRainbowConsole  bool                        `mapstructure:"enableRainbowConsole" json:"enableRainbowConsole" yaml:"enableRainbowConsole"`
-	TimeFormat            string                      `mapstructure:"timeFormat" json:"timeFormat" yaml:"timeFormat"`
-	SizeRollingFileConfig LoggerSizeRollingFileConfig `mapstructure:"sizeRollingFileConfig" json:"sizeRollingFileConfig" yaml:"sizeRollingFileConfig"`
-	TimeRollingFileConfig LoggerTimeRollingFileConfig `mapstructure:"timeRollingFileConfig" json:"timeRollingFileConfig" yaml:"timeRollingFileConfig"`
+	Enable                  bool                          `mapstructure:"enable" json:"enable" yaml:"enable"`
+	Level                   string                        `mapstructure:"level" json:"level" yaml:"level"`
+	Label                   string                        `mapstructure:"label" json:"label" yaml:"label"`
+	Stack                   bool                          `mapstructure:"stack" json:"stack" yaml:"stack"`
+	StackLevel              string                        `mapstructure:"stackLevel" json:"stackLevel" yaml:"stackLevel"`
+	EnableConsolePrinting   bool                          `mapstructure:"enableConsolePrinting" json:"enableConsolePrinting" yaml:"enableConsolePrinting"`
+	EnableRainbowConsole    bool                          `mapstructure:"enableRainbowConsole" json:"enableRainbowConsole" yaml:"enableRainbowConsole"`
+	TimeFormat              string                        `mapstructure:"timeFormat" json:"timeFormat" yaml:"timeFormat"`
+	VModule                 string                        `mapstructure:"vmodule" json:"vmodule" yaml:"vmodule"`
+	MetaKeys                []string                      `mapstructure:"metaKeys" json:"metaKeys" yaml:"metaKeys"`
+	ConsoleColors           map[string][]string           `mapstructure:"consoleColors" json:"consoleColors" yaml:"consoleColors"`
+	FieldNames              LoggerFieldNamesConfig        `mapstructure:"fieldNames" json:"fieldNames" yaml:"fieldNames"`
+	CallerFormat            string                        `mapstructure:"callerFormat" json:"callerFormat" yaml:"callerFormat"`
+	CallerSkipFrameCount    int                           `mapstructure:"callerSkipFrameCount" json:"callerSkipFrameCount" yaml:"callerSkipFrameCount"`
+	DurationFormat          string                        `mapstructure:"durationFormat" json:"durationFormat" yaml:"durationFormat"`
+	SizeRollingFileConfig   LoggerSizeRollingFileConfig   `mapstructure:"sizeRollingFileConfig" json:"sizeRollingFileConfig" yaml:"sizeRollingFileConfig"`
+	TimeRollingFileConfig   LoggerTimeRollingFileConfig   `mapstructure:"timeRollingFileConfig" json:"timeRollingFileConfig" yaml:"timeRollingFileConfig"`
+	HybridRollingFileConfig LoggerHybridRollingFileConfig `mapstructure:"hybridRollingFileConfig" json:"hybridRollingFileConfig" yaml:"hybridRollingFileConfig"`
+	LevelSplitFileConfig    LoggerLevelSplitFileConfig    `mapstructure:"levelSplitFileConfig" json:"levelSplitFileConfig" yaml:"levelSplitFileConfig"`
+	LabelRouteFileConfig    LoggerLabelRouteFileConfig    `mapstructure:"labelRouteFileConfig" json:"labelRouteFileConfig" yaml:"labelRouteFileConfig"`
+	Writers                 []LoggerWriterConfig          `mapstructure:"writers" json:"writers" yaml:"writers"`
 }
 
+// LoggerFieldNamesConfig overrides the names of fields printed, empty names keep the current ones.
+type LoggerFieldNamesConfig struct {
+	Message    string `mapstructure:"message" json:"message" yaml:"message"`
+	Error      string `mapstructure:"error" json:"error" yaml:"error"`
+	ErrorStack string `mapstructure:"errorStack" json:"errorStack" yaml:"errorStack"`
+	Stack      string `mapstructure:"stack" json:"stack" yaml:"stack"`
+	Time       string `mapstructure:"time" json:"time" yaml:"time"`
+	Level      string `mapstructure:"level" json:"level" yaml:"level"`
+	Label      string `mapstructure:"label" json:"label" yaml:"label"`
+	Caller     string `mapstructure:"caller" json:"caller" yaml:"caller"`
+	Func       string `mapstructure:"func" json:"func" yaml:"func"`
+}
+
+// LoggerTimeRollingFileConfig describes the file rolled by time period.
+// Records are written into LogFileBaseName, which is renamed with the time of the period when rotating,
+// e.g. rainbow.20240219.log, and compressed if Compress set. MaxBackups of zero keeps no backup,
+// while a negative one keeps all.
+//
+// NOTICE: before the writer was provided by rainbowlog, MaxBackups counted rolling periods and
+// a negative one was treated as zero, configs relying on that should be checked when upgrading.
 type LoggerTimeRollingFileConfig struct {
 	Enable            bool                     `mapstructure:"enable" json:"enable" yaml:"enable"`
 	LogFilePath       string                   `mapstructure:"logFilePath" json:"logFilePath" yaml:"logFilePath"`
@@ -30,8 +78,17 @@ type LoggerTimeRollingFileConfig struct {
 	Encoder           string                   `mapstructure:"encoder" json:"encoder" yaml:"encoder"`
 	UseBufferedWriter bool                     `mapstructure:"useBufferedWriter" json:"useBufferedWriter" yaml:"useBufferedWriter"`
 	WriterBufferSize  string                   `mapstructure:"writerBufferSize" json:"writerBufferSize" yaml:"writerBufferSize"`
+	FlushInterval     string                   `mapstructure:"flushInterval" json:"flushInterval" yaml:"flushInterval"`
+	Compress          string                   `mapstructure:"compress" json:"compress" yaml:"compress"`
 }
 
+// LoggerSizeRollingFileConfig describes the file rolled by file size.
+// Records are written into LogFileBaseName, which is renamed with the time it was opened when rotating,
+// e.g. rainbow.20240219_19_50_09.log, and compressed if Compress set. MaxBackups of zero keeps no backup,
+// while a negative one keeps all.
+//
+// NOTICE: before the writer was provided by rainbowlog, backups were named rainbow.1.log, rainbow.2.log, etc.,
+// which are neither counted nor removed now, and MaxBackups of zero kept the latest backup.
 type LoggerSizeRollingFileConfig struct {
 	Enable            bool   `mapstructure:"enable" json:"enable" yaml:"enable"`
 	LogFilePath       string `mapstructure:"logFilePath" json:"logFilePath" yaml:"logFilePath"`
@@ -41,6 +98,95 @@ type LoggerSizeRollingFileConfig struct {
 	Encoder           string `mapstructure:"encoder" json:"encoder" yaml:"encoder"`
 	UseBufferedWriter bool   `mapstructure:"useBufferedWriter" json:"useBufferedWriter" yaml:"useBufferedWriter"`
 	WriterBufferSize  string `mapstructure:"writerBufferSize" json:"writerBufferSize" yaml:"writerBufferSize"`
+	FlushInterval     string `mapstructure:"flushInterval" json:"flushInterval" yaml:"flushInterval"`
+	Compress          string `mapstructure:"compress" json:"compress" yaml:"compress"`
+}
+
+// LoggerHybridRollingFileConfig describes the file rolled by time period or by file size, whichever comes first.
+// Records are written into LogFileBaseName, which is renamed with the time of the period and the sequence
+// of the segment in the period when rotating, e.g. rainbow.20240219.3.log, and compressed if Compress set.
+// Backups, compressed or not, are removed from the oldest while any limit is exceeded:
+// MaxBackups limits the number of backups, zero keeps no backup while a negative one keeps all,
+// MaxAge limits the duration since a backup was last written, e.g. '168h', and MaxTotalSize limits
+// the total size of backups, e.g. '1G'. Empty MaxAge or MaxTotalSize means no limit.
+type LoggerHybridRollingFileConfig struct {
+	Enable            bool                     `mapstructure:"enable" json:"enable" yaml:"enable"`
+	LogFilePath       string                   `mapstructure:"logFilePath" json:"logFilePath" yaml:"logFilePath"`
+	LogFileBaseName   string                   `mapstructure:"logFileBaseName" json:"logFileBaseName" yaml:"logFileBaseName"`
+	RollingPeriod     filewriter.RollingPeriod `mapstructure:"rollingPeriod" json:"rollingPeriod" yaml:"rollingPeriod"`
+	FileSizeLimit     string                   `mapstructure:"fileSizeLimit" json:"fileSizeLimit" yaml:"fileSizeLimit"`
+	MaxBackups        int                      `mapstructure:"maxBackups" json:"maxBackups" yaml:"maxBackups"`
+	MaxAge            string                   `mapstructure:"maxAge" json:"maxAge" yaml:"maxAge"`
+	MaxTotalSize      string                   `mapstructure:"maxTotalSize" json:"maxTotalSize" yaml:"maxTotalSize"`
+	Encoder           string                   `mapstructure:"encoder" json:"encoder" yaml:"encoder"`
+	UseBufferedWriter bool                     `mapstructure:"useBufferedWriter" json:"useBufferedWriter" yaml:"useBufferedWriter"`
+	WriterBufferSize  string                   `mapstructure:"writerBufferSize" json:"writerBufferSize" yaml:"writerBufferSize"`
+	FlushInterval     string                   `mapstructure:"flushInterval" json:"flushInterval" yaml:"flushInterval"`
+	Compress          string                   `mapstructure:"compress" json:"compress" yaml:"compress"`
+}
+
+// LoggerLevelSplitFileConfig describes the files split by level.
+// Records of each level in Levels are written into its own file named by inserting the lower case level name
+// before the extension of LogFileBaseName, e.g. rainbow.error.log, records of other levels are written into
+// LogFileBaseName. Each file is rolled by time period or file size, whichever comes first.
+type LoggerLevelSplitFileConfig struct {
+	Enable            bool                     `mapstructure:"enable" json:"enable" yaml:"enable"`
+	LogFilePath       string                   `mapstructure:"logFilePath" json:"logFilePath" yaml:"logFilePath"`
+	LogFileBaseName   string                   `mapstructure:"logFileBaseName" json:"logFileBaseName" yaml:"logFileBaseName"`
+	Levels            []string                 `mapstructure:"levels" json:"levels" yaml:"levels"`
+	RollingPeriod     filewriter.RollingPeriod `mapstructure:"rollingPeriod" json:"rollingPeriod" yaml:"rollingPeriod"`
+	FileSizeLimit     string                   `mapstructure:"fileSizeLimit" json:"fileSizeLimit" yaml:"fileSizeLimit"`
+	MaxBackups        int                      `mapstructure:"maxBackups" json:"maxBackups" yaml:"maxBackups"`
+	MaxAge            string                   `mapstructure:"maxAge" json:"maxAge" yaml:"maxAge"`
+	MaxTotalSize      string                   `mapstructure:"maxTotalSize" json:"maxTotalSize" yaml:"maxTotalSize"`
+	Encoder           string                   `mapstructure:"encoder" json:"encoder" yaml:"encoder"`
+	UseBufferedWriter bool                     `mapstructure:"useBufferedWriter" json:"useBufferedWriter" yaml:"useBufferedWriter"`
+	WriterBufferSize  string                   `mapstructure:"writerBufferSize" json:"writerBufferSize" yaml:"writerBufferSize"`
+	FlushInterval     string                   `mapstructure:"flushInterval" json:"flushInterval" yaml:"flushInterval"`
+	Compress          string                   `mapstructure:"compress" json:"compress" yaml:"compress"`
+}
+
+// LoggerLabelRouteFileConfig describes the files which records are routed to by label.
+// Records not matching any route are written into DefaultLogFileBaseName, or discarded if it is empty.
+// Each file is rolled by time period or file size, whichever comes first.
+type LoggerLabelRouteFileConfig struct {
+	Enable                 bool                     `mapstructure:"enable" json:"enable" yaml:"enable"`
+	LogFilePath            string                   `mapstructure:"logFilePath" json:"logFilePath" yaml:"logFilePath"`
+	DefaultLogFileBaseName string                   `mapstructure:"defaultLogFileBaseName" json:"defaultLogFileBaseName" yaml:"defaultLogFileBaseName"`
+	Routes                 []LoggerLabelRouteConfig `mapstructure:"routes" json:"routes" yaml:"routes"`
+	RollingPeriod          filewriter.RollingPeriod `mapstructure:"rollingPeriod" json:"rollingPeriod" yaml:"rollingPeriod"`
+	FileSizeLimit          string                   `mapstructure:"fileSizeLimit" json:"fileSizeLimit" yaml:"fileSizeLimit"`
+	MaxBackups             int                      `mapstructure:"maxBackups" json:"maxBackups" yaml:"maxBackups"`
+	MaxAge                 string                   `mapstructure:"maxAge" json:"maxAge" yaml:"maxAge"`
+	MaxTotalSize           string                   `mapstructure:"maxTotalSize" json:"maxTotalSize" yaml:"maxTotalSize"`
+	Encoder                string                   `mapstructure:"encoder" json:"encoder" yaml:"encoder"`
+	UseBufferedWriter      bool                     `mapstructure:"useBufferedWriter" json:"useBufferedWriter" yaml:"useBufferedWriter"`
+	WriterBufferSize       string                   `mapstructure:"writerBufferSize" json:"writerBufferSize" yaml:"writerBufferSize"`
+	FlushInterval          string                   `mapstructure:"flushInterval" json:"flushInterval" yaml:"flushInterval"`
+	Compress               string                   `mapstructure:"compress" json:"compress" yaml:"compress"`
+}
+
+// LoggerLabelRouteConfig routes records labeled Label into the file LogFileBaseName.
+// Label ending with "*" matches labels by prefix, e.g. 'db|*' matches 'db' and 'db|query'.
+// Routes with the same LogFileBaseName share the file.
+type LoggerLabelRouteConfig struct {
+	Label           string `mapstructure:"label" json:"label" yaml:"label"`
+	LogFileBaseName string `mapstructure:"logFileBaseName" json:"logFileBaseName" yaml:"logFileBaseName"`
+}
+
+// LoggerWriterConfig is the configuration of a writer in the writers list.
+// Type is the type of the writer registered, e.g. stdout, stderr, file, sizeRolling, timeRolling,
+// hybridRolling, syslog or network, and Options are the type-specific settings of the writer.
+// Records below MinLevel are discarded by the writer, all records are written if it is empty.
+type LoggerWriterConfig struct {
+	Enable            bool           `mapstructure:"enable" json:"enable" yaml:"enable"`
+	Type              string         `mapstructure:"type" json:"type" yaml:"type"`
+	Encoder           string         `mapstructure:"encoder" json:"encoder" yaml:"encoder"`
+	MinLevel          string         `mapstructure:"minLevel" json:"minLevel" yaml:"minLevel"`
+	UseBufferedWriter bool           `mapstructure:"useBufferedWriter" json:"useBufferedWriter" yaml:"useBufferedWriter"`
+	WriterBufferSize  string         `mapstructure:"writerBufferSize" json:"writerBufferSize" yaml:"writerBufferSize"`
+	FlushInterval     string         `mapstructure:"flushInterval" json:"flushInterval" yaml:"flushInterval"`
+	Options           map[string]any `mapstructure:"options" json:"options" yaml:"options"`
 }
 
 func DefaultLoggerConfig() LoggerConfig {
@@ -49,9 +195,17 @@ func DefaultLoggerConfig() LoggerConfig {
 		Level:                 "DEBUG",
 		Label:                 "",
 		Stack:                 false,
+		StackLevel:            "",
 		EnableConsolePrinting: true,
 		EnableRainbowConsole:  true,
-		TimeFormat:            "2006-01-02 15:04:05.000",
+		TimeFormat:            "",
+		VModule:               "",
+		MetaKeys:              nil,
+		ConsoleColors:         map[string][]string{},
+		FieldNames:            LoggerFieldNamesConfig{},
+		CallerFormat:          "",
+		CallerSkipFrameCount:  0,
+		DurationFormat:        "",
 		SizeRollingFileConfig: LoggerSizeRollingFileConfig{
 			Enable:            false,
 			LogFilePath:       "./log",
@@ -61,6 +215,8 @@ func DefaultLoggerConfig() LoggerConfig {
 			Encoder:           "json",
 			UseBufferedWriter: true,
 			WriterBufferSize:  "4K",
+			FlushInterval:     "",
+			Compress:          "",
 		},
 		TimeRollingFileConfig: LoggerTimeRollingFileConfig{
 			Enable:            false,
@@ -71,11 +227,67 @@ func DefaultLoggerConfig() LoggerConfig {
 			Encoder:           "json",
 			UseBufferedWriter: true,
 			WriterBufferSize:  "4K",
+			FlushInterval:     "",
+			Compress:          "",
+		},
+		HybridRollingFileConfig: LoggerHybridRollingFileConfig{
+			Enable:            false,
+			LogFilePath:       "./log",
+			LogFileBaseName:   "rainbow.h.log",
+			RollingPeriod:     "DAY",
+			FileSizeLimit:     "100M",
+			MaxBackups:        -1,
+			MaxAge:            "168h",
+			MaxTotalSize:      "1G",
+			Encoder:           "json",
+			UseBufferedWriter: true,
+			WriterBufferSize:  "4K",
+			FlushInterval:     "",
+			Compress:          "gzip",
+		},
+		LevelSplitFileConfig: LoggerLevelSplitFileConfig{
+			Enable:            false,
+			LogFilePath:       "./log",
+			LogFileBaseName:   "rainbow.log",
+			Levels:            []string{"DEBUG", "INFO", "ERROR"},
+			RollingPeriod:     "DAY",
+			FileSizeLimit:     "100M",
+			MaxBackups:        -1,
+			MaxAge:            "168h",
+			MaxTotalSize:      "1G",
+			Encoder:           "json",
+			UseBufferedWriter: true,
+			WriterBufferSize:  "4K",
+			FlushInterval:     "",
+			Compress:          "gzip",
 		},
+		LabelRouteFileConfig: LoggerLabelRouteFileConfig{
+			Enable:                 false,
+			LogFilePath:            "./log",
+			DefaultLogFileBaseName: "rainbow.log",
+			Routes: []LoggerLabelRouteConfig{
+				{Label: "audit", LogFileBaseName: "audit.log"},
+				{Label: "db|*", LogFileBaseName: "db.log"},
+			},
+			RollingPeriod:     "DAY",
+			FileSizeLimit:     "100M",
+			MaxBackups:        -1,
+			MaxAge:            "168h",
+			MaxTotalSize:      "1G",
+			Encoder:           "json",
+			UseBufferedWriter: true,
+			WriterBufferSize:  "4K",
+			FlushInterval:     "",
+			Compress:          "gzip",
+		},
+		Writers: []LoggerWriterConfig{},
 	}
 }
 
-// LoadLoggerConfigFromFile loads the logger configuration from a file.
+// LoadLoggerConfigFromFile loads the logger configuration from a file,
+// then overlays environment variables of DefaultEnvPrefix, see LoggerConfig.OverlayEnv.
+// Fields missing in the file keep the values of DefaultLoggerConfig rather than zero values,
+// e.g. enable is true if it is missing, while lists and maps in the file replace the default ones as a whole.
 func LoadLoggerConfigFromFile(configFile string) (*LoggerConfig, error) {
 	fileName := filepath.Base(configFile)
 	fileType := strings.TrimPrefix(filepath.Ext(fileName), ".")
@@ -85,11 +297,16 @@ func LoadLoggerConfigFromFile(configFile string) (*LoggerConfig, error) {
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
 	}
-	cfg := new(LoggerConfig)
-	if err := v.UnmarshalKey("rainbowlog", cfg); err != nil {
+	cfg := DefaultLoggerConfig()
+	if err := v.UnmarshalKey("rainbowlog", &cfg, func(dc *mapstructure.DecoderConfig) {
+		dc.ZeroFields = true
+	}); err != nil {
+		return nil, err
+	}
+	if err := cfg.OverlayEnv(DefaultEnvPrefix); err != nil {
 		return nil, err
 	}
-	return cfg, nil
+	return &cfg, nil
 }
 
 // WriteConfigToFile writes the logger configuration to a file.
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -47,6 +48,7 @@ func TestLoadLoggerConfigFromFile(t *testing.T) {
 		require.Equal(t, cfg.EnableConsolePrinting, cfg2.EnableConsolePrinting)
 		require.Equal(t, cfg.EnableRainbowConsole, cfg2.EnableRainbowConsole)
 		require.Equal(t, cfg.TimeFormat, cfg2.TimeFormat)
+		require.Equal(t, cfg.VModule, cfg2.VModule)
 		require.Equal(t, cfg.SizeRollingFileConfig.Enable, cfg2.SizeRollingFileConfig.Enable)
 		require.Equal(t, cfg.SizeRollingFileConfig.LogFilePath, cfg2.SizeRollingFileConfig.LogFilePath)
 		require.Equal(t, cfg.SizeRollingFileConfig.LogFileBaseName, cfg2.SizeRollingFileConfig.LogFileBaseName)
@@ -54,6 +56,8 @@ func TestLoadLoggerConfigFromFile(t *testing.T) {
 		require.Equal(t, cfg.SizeRollingFileConfig.FileSizeLimit, cfg2.SizeRollingFileConfig.FileSizeLimit)
 		require.Equal(t, cfg.SizeRollingFileConfig.UseBufferedWriter, cfg2.SizeRollingFileConfig.UseBufferedWriter)
 		require.Equal(t, cfg.SizeRollingFileConfig.WriterBufferSize, cfg2.SizeRollingFileConfig.WriterBufferSize)
+		require.Equal(t, cfg.SizeRollingFileConfig.Compress, cfg2.SizeRollingFileConfig.Compress)
+		require.Equal(t, cfg.SizeRollingFileConfig.FlushInterval, cfg2.SizeRollingFileConfig.FlushInterval)
 		require.Equal(t, cfg.TimeRollingFileConfig.Enable, cfg2.TimeRollingFileConfig.Enable)
 		require.Equal(t, cfg.TimeRollingFileConfig.LogFilePath, cfg2.TimeRollingFileConfig.LogFilePath)
 		require.Equal(t, cfg.TimeRollingFileConfig.LogFileBaseName, cfg2.TimeRollingFileConfig.LogFileBaseName)
@@ -61,5 +65,203 @@ func TestLoadLoggerConfigFromFile(t *testing.T) {
 		require.Equal(t, cfg.TimeRollingFileConfig.RollingPeriod, cfg2.TimeRollingFileConfig.RollingPeriod)
 		require.Equal(t, cfg.TimeRollingFileConfig.UseBufferedWriter, cfg2.TimeRollingFileConfig.UseBufferedWriter)
 		require.Equal(t, cfg.TimeRollingFileConfig.WriterBufferSize, cfg2.TimeRollingFileConfig.WriterBufferSize)
+		require.Equal(t, cfg.TimeRollingFileConfig.Compress, cfg2.TimeRollingFileConfig.Compress)
+		require.Equal(t, cfg.TimeRollingFileConfig.FlushInterval, cfg2.TimeRollingFileConfig.FlushInterval)
+		require.Equal(t, cfg.HybridRollingFileConfig, cfg2.HybridRollingFileConfig)
+		require.Equal(t, cfg.LevelSplitFileConfig, cfg2.LevelSplitFileConfig)
+		require.Equal(t, cfg.LabelRouteFileConfig, cfg2.LabelRouteFileConfig)
+		requireWritersEqual(t, cfg.Writers, cfg2.Writers)
 	}
 }
+
+func TestLoadSampleConfigFiles(t *testing.T) {
+	cfg := DefaultLoggerConfig()
+	// samples show a writer disabled, while the default config has no writers
+	sampleWriters := []LoggerWriterConfig{{
+		Enable:           false,
+		Type:             "file",
+		Encoder:          "json",
+		MinLevel:         "ERROR",
+		WriterBufferSize: "4K",
+		Options:          map[string]any{"logFilePath": "./log", "logFileBaseName": "error.log"},
+	}}
+	for _, fileName := range []string{"rainbowlog.toml", "rainbowlog.yaml", "rainbowlog.json"} {
+		t.Run(fileName, func(t *testing.T) {
+			cfg2, err := LoadLoggerConfigFromFile(fileName)
+			require.NoError(t, err)
+			require.Equal(t, cfg.LabelRouteFileConfig, cfg2.LabelRouteFileConfig)
+			requireWritersEqual(t, sampleWriters, cfg2.Writers)
+			require.Empty(t, cfg2.MetaKeys)
+			require.Equal(t, map[string][]string{"message": {"hlBlue"}}, cfg2.ConsoleColors)
+			require.Equal(t, cfg.StackLevel, cfg2.StackLevel)
+			require.Equal(t, cfg.FieldNames, cfg2.FieldNames)
+			require.Equal(t, cfg.CallerFormat, cfg2.CallerFormat)
+			require.Equal(t, cfg.DurationFormat, cfg2.DurationFormat)
+		})
+	}
+}
+
+// requireWritersEqual compares writer configs, keys of options are compared case-insensitively
+// since they are converted to lower case when loading.
+func requireWritersEqual(t *testing.T, expected, actual []LoggerWriterConfig) {
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		eOpts, aOpts := make(map[string]any, len(e.Options)), make(map[string]any, len(a.Options))
+		for k, v := range e.Options {
+			eOpts[strings.ToLower(k)] = v
+		}
+		for k, v := range a.Options {
+			aOpts[strings.ToLower(k)] = v
+		}
+		require.Equal(t, eOpts, aOpts)
+		e.Options, a.Options = nil, nil
+		require.Equal(t, e, a)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultLoggerConfig()
+	require.NoError(t, cfg.Validate())
+
+	cfg.Level = "VERBOSE"
+	cfg.StackLevel = "LOUD"
+	cfg.DurationFormat = "ms"
+	cfg.CallerSkipFrameCount = -1
+	cfg.SizeRollingFileConfig.Enable = true
+	cfg.SizeRollingFileConfig.FileSizeLimit = "10X"
+	cfg.TimeRollingFileConfig.Enable = true
+	cfg.TimeRollingFileConfig.RollingPeriod = "WEEK"
+	cfg.TimeRollingFileConfig.FlushInterval = "soon"
+	cfg.HybridRollingFileConfig.Enable = true
+	cfg.SizeRollingFileConfig.Compress = "gz"  // alias of gzip
+	cfg.TimeRollingFileConfig.Compress = "zst" // alias of zstd
+	cfg.HybridRollingFileConfig.Compress = "lz4"
+	cfg.LevelSplitFileConfig.Enable = true
+	cfg.LevelSplitFileConfig.Levels = []string{"INFO", "NOTICE"}
+	cfg.LabelRouteFileConfig.Enable = true
+	cfg.LabelRouteFileConfig.Routes = append(cfg.LabelRouteFileConfig.Routes, LoggerLabelRouteConfig{Label: "cache"})
+	cfg.Writers = []LoggerWriterConfig{
+		{Enable: true, MinLevel: "LOUD"},
+		{Enable: false, Type: "", MinLevel: "LOUD"},
+	}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	var errs ValidationError
+	require.ErrorAs(t, err, &errs)
+	fields := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, fe.Field)
+	}
+	require.Equal(t, []string{
+		"level",
+		"stackLevel",
+		"durationFormat",
+		"callerSkipFrameCount",
+		"sizeRollingFileConfig.fileSizeLimit",
+		"timeRollingFileConfig.rollingPeriod",
+		"timeRollingFileConfig.flushInterval",
+		"hybridRollingFileConfig.compress",
+		"levelSplitFileConfig.levels[1]",
+		"labelRouteFileConfig.routes[2].logFileBaseName",
+		"writers[0].type",
+		"writers[0].minLevel",
+	}, fields)
+	require.Contains(t, err.Error(), `12 problem(s) found: level: unknown level "VERBOSE"`)
+
+	cfg.Enable = false
+	require.NoError(t, cfg.Validate())
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	t.Setenv("RAINBOWLOG_LEVEL", "WARN")
+	t.Setenv("RAINBOWLOG_ENABLECONSOLEPRINTING", "false")
+	t.Setenv("RAINBOWLOG_SIZEROLLINGFILECONFIG_ENABLE", "true")
+	t.Setenv("RAINBOWLOG_SIZEROLLINGFILECONFIG_MAXBACKUPS", "3")
+	t.Setenv("RAINBOWLOG_LEVELSPLITFILECONFIG_LEVELS", "INFO, ERROR")
+	t.Setenv("RAINBOWLOG_WRITERS_0_ENABLE", "true")
+	t.Setenv("RAINBOWLOG_WRITERS_0_TYPE", "stdout")
+	t.Setenv("RAINBOWLOG_WRITERS_1_ENABLE", "true")
+	t.Setenv("RAINBOWLOG_WRITERS_1_TYPE", "network")
+	t.Setenv("RAINBOWLOG_WRITERS_1_OPTIONS_ADDRESS", "127.0.0.1:514")
+
+	cfg, err := LoadFromEnv(DefaultEnvPrefix)
+	require.NoError(t, err)
+	require.Equal(t, "WARN", cfg.Level)
+	require.False(t, cfg.EnableConsolePrinting)
+	require.True(t, cfg.SizeRollingFileConfig.Enable)
+	require.Equal(t, 3, cfg.SizeRollingFileConfig.MaxBackups)
+	require.Equal(t, []string{"INFO", "ERROR"}, cfg.LevelSplitFileConfig.Levels)
+	require.Len(t, cfg.Writers, 2)
+	require.Equal(t, LoggerWriterConfig{Enable: true, Type: "stdout"}, cfg.Writers[0])
+	require.Equal(t, LoggerWriterConfig{
+		Enable:  true,
+		Type:    "network",
+		Options: map[string]any{"address": "127.0.0.1:514"},
+	}, cfg.Writers[1])
+
+	t.Run("CustomPrefix", func(t *testing.T) {
+		t.Setenv("APP_LOG_LEVEL", "ERROR")
+		cfg, err := LoadFromEnv("APP_LOG")
+		require.NoError(t, err)
+		require.Equal(t, "ERROR", cfg.Level)
+		require.Empty(t, cfg.Writers)
+
+		cfg, err = LoadFromEnv("app_log_")
+		require.NoError(t, err)
+		require.Equal(t, "ERROR", cfg.Level)
+	})
+
+	t.Run("EmptyPrefix", func(t *testing.T) {
+		t.Setenv("LEVEL", "ERROR")
+		_, err := LoadFromEnv("")
+		require.ErrorContains(t, err, "prefix")
+		_, err = LoadFromEnv("_")
+		require.ErrorContains(t, err, "prefix")
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		t.Setenv("RAINBOWLOG_STACK", "yes")
+		_, err := LoadFromEnv(DefaultEnvPrefix)
+		require.ErrorContains(t, err, "RAINBOWLOG_STACK")
+	})
+
+	t.Run("IndexOutOfRange", func(t *testing.T) {
+		t.Setenv("RAINBOWLOG_WRITERS_3_TYPE", "stdout")
+		_, err := LoadFromEnv(DefaultEnvPrefix)
+		require.ErrorContains(t, err, "RAINBOWLOG_WRITERS_3 out of range")
+	})
+}
+
+func TestLoadLoggerConfigFromFileWithEnv(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "rainbowlog.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte(`rainbowlog:
+  enable: true
+  level: INFO
+  label: app
+  writers:
+    - enable: true
+      type: file
+      encoder: text
+      options:
+        logFilePath: ./log
+`), 0644))
+	t.Setenv("RAINBOWLOG_LABEL", "env")
+	t.Setenv("RAINBOWLOG_WRITERS_0_OPTIONS_LOGFILEBASENAME", "app.log")
+
+	cfg, err := LoadLoggerConfigFromFile(cfgFile)
+	require.NoError(t, err)
+	// the file overlays defaults
+	require.Equal(t, "INFO", cfg.Level)
+	require.Equal(t, DefaultLoggerConfig().TimeFormat, cfg.TimeFormat)
+	require.Equal(t, DefaultLoggerConfig().HybridRollingFileConfig, cfg.HybridRollingFileConfig)
+	// environment variables overlay the file
+	require.Equal(t, "env", cfg.Label)
+	require.Equal(t, []LoggerWriterConfig{{
+		Enable:  true,
+		Type:    "file",
+		Encoder: "text",
+		Options: map[string]any{"logfilepath": "./log", "logfilebasename": "app.log"},
+	}}, cfg.Writers)
+}
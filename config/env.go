@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultEnvPrefix is the prefix of environment variables overlaid by LoadLoggerConfigFromFile.
+const DefaultEnvPrefix = "RAINBOWLOG"
+
+// LoadFromEnv loads the logger configuration from the default configuration
+// overlaid with environment variables of the prefix, see LoggerConfig.OverlayEnv.
+// An error is returned if the prefix is empty.
+func LoadFromEnv(prefix string) (*LoggerConfig, error) {
+	cfg := DefaultLoggerConfig()
+	if err := cfg.OverlayEnv(prefix); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// OverlayEnv overlays values of environment variables onto the config.
+// The name of the variable of a field is the prefix followed by the names of the field path in upper case,
+// joined by "_", e.g. RAINBOWLOG_LEVEL or RAINBOWLOG_SIZEROLLINGFILECONFIG_ENABLE for the prefix RAINBOWLOG.
+//
+// Elements of lists are addressed by index, e.g. RAINBOWLOG_WRITERS_0_TYPE, and an element is appended
+// if the index equals the length of the list. Lists of strings are comma-separated,
+// e.g. RAINBOWLOG_LEVELSPLITFILECONFIG_LEVELS=INFO,ERROR.
+// Entries of maps are set by the key, e.g. RAINBOWLOG_CONSOLECOLORS_MESSAGE=hlBlue,bold, and
+// options of writers are set by the option name, e.g. RAINBOWLOG_WRITERS_0_OPTIONS_LOGFILEPATH,
+// which replace the entry of the same key in any case.
+//
+// The prefix is required, so that unrelated variables such as PATH or HOME are never taken as fields,
+// and a trailing "_" of it is optional, e.g. "APP_LOG_" is the same as "APP_LOG".
+//
+// Variables not matching any field are ignored, an error is returned if any value fails to be parsed.
+func (c *LoggerConfig) OverlayEnv(prefix string) error {
+	prefix = strings.ToUpper(strings.TrimRight(prefix, "_"))
+	if prefix == "" {
+		return errors.New("prefix of environment variables is required")
+	}
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		name = strings.ToUpper(name)
+		if strings.HasPrefix(name, prefix+"_") {
+			env[name] = value
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return overlayEnv(env, prefix, reflect.ValueOf(c).Elem())
+}
+
+// envName joins the name of the parent and the name of the field.
+func envName(parent, name string) string {
+	if parent == "" {
+		return strings.ToUpper(name)
+	}
+	return parent + "_" + strings.ToUpper(name)
+}
+
+func overlayEnv(env map[string]string, name string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			tag, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("mapstructure"), ",")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			if err := overlayEnv(env, envName(name, tag), v.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			value, ok := env[name]
+			if !ok {
+				return nil
+			}
+			v.Set(reflect.ValueOf(splitList(value)))
+			return nil
+		}
+		return overlayEnvSlice(env, name, v)
+	case reflect.Map:
+		return overlayEnvMap(env, name, v)
+	}
+
+	value, ok := env[name]
+	if !ok {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value of environment variable %s: %w", name, err)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid value of environment variable %s: %w", name, err)
+		}
+		v.SetInt(i)
+	default:
+		return fmt.Errorf("environment variable %s is not supported", name)
+	}
+	return nil
+}
+
+// overlayEnvSlice overlays elements of the slice addressed by index, appending elements in the order of indexes.
+func overlayEnvSlice(env map[string]string, name string, v reflect.Value) error {
+	indexes := make(map[int]struct{})
+	for key := range env {
+		rest, ok := strings.CutPrefix(key, name+"_")
+		if !ok {
+			continue
+		}
+		idx, _, _ := strings.Cut(rest, "_")
+		if i, err := strconv.Atoi(idx); err == nil && i >= 0 {
+			indexes[i] = struct{}{}
+		}
+	}
+	sorted := make([]int, 0, len(indexes))
+	for i := range indexes {
+		sorted = append(sorted, i)
+	}
+	sort.Ints(sorted)
+	for _, i := range sorted {
+		switch {
+		case i == v.Len():
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+		case i > v.Len():
+			return fmt.Errorf("index of environment variable %s_%d out of range, elements should be appended in order", name, i)
+		}
+		if err := overlayEnv(env, envName(name, strconv.Itoa(i)), v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overlayEnvMap sets values of the map by names in lower case, replacing keys of the same name in any case.
+func overlayEnvMap(env map[string]string, name string, v reflect.Value) error {
+	for key, value := range env {
+		rest, ok := strings.CutPrefix(key, name+"_")
+		if !ok || rest == "" {
+			continue
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, k := range v.MapKeys() {
+			if strings.EqualFold(k.String(), rest) {
+				v.SetMapIndex(k, reflect.Value{})
+			}
+		}
+		switch elemType := v.Type().Elem(); {
+		case elemType.Kind() == reflect.Interface, elemType.Kind() == reflect.String:
+			v.SetMapIndex(reflect.ValueOf(strings.ToLower(rest)), reflect.ValueOf(value).Convert(elemType))
+		case elemType.Kind() == reflect.Slice && elemType.Elem().Kind() == reflect.String:
+			v.SetMapIndex(reflect.ValueOf(strings.ToLower(rest)), reflect.ValueOf(splitList(value)))
+		default:
+			return fmt.Errorf("environment variable %s is not supported", key)
+		}
+	}
+	return nil
+}
+
+// splitList splits the comma-separated list, empty items are dropped.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rambollwong/rainbowcat/util"
+	"github.com/rambollwong/rainbowcat/writer/filewriter"
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+// FieldError describes a problem of a config field.
+type FieldError struct {
+	Field string // Path of the field, e.g. "sizeRollingFileConfig.fileSizeLimit" or "writers[0].minLevel"
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError collects all problems found in a LoggerConfig.
+type ValidationError []*FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return fmt.Sprintf("invalid logger config, %d problem(s) found: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, fe := range e {
+		errs = append(errs, fe)
+	}
+	return errs
+}
+
+// Add adds a problem of the field to the ValidationError.
+func (e *ValidationError) Add(field string, err error) {
+	*e = append(*e, &FieldError{Field: field, Err: err})
+}
+
+// Addf adds a problem of the field with a formatted message to the ValidationError.
+func (e *ValidationError) Addf(field string, format string, args ...any) {
+	e.Add(field, fmt.Errorf(format, args...))
+}
+
+// Validate checks the config and returns a ValidationError collecting all problems found, or nil if no problem.
+// Sections disabled are not checked, and nothing is checked if the logger is disabled.
+//
+// NOTICE: encoders, writer types, vmodule, meta keys, console colors and caller format depend on
+// what is registered in the rainbowlog package, they are checked by rainbowlog.NewFromConfig
+// together with problems found here.
+func (c LoggerConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	var errs ValidationError
+	validateLevel(&errs, "level", c.Level)
+	if c.StackLevel != "" {
+		validateLevel(&errs, "stackLevel", c.StackLevel)
+	}
+	switch strings.ToLower(c.DurationFormat) {
+	case "", "float", "int":
+	default:
+		errs.Addf("durationFormat", "unknown duration format %q, 'float' or 'int' expected", c.DurationFormat)
+	}
+	if c.CallerSkipFrameCount < 0 {
+		errs.Addf("callerSkipFrameCount", "negative count %d", c.CallerSkipFrameCount)
+	}
+
+	if src := c.SizeRollingFileConfig; src.Enable {
+		const prefix = "sizeRollingFileConfig."
+		validateRequired(&errs, prefix+"logFileBaseName", src.LogFileBaseName)
+		validateSize(&errs, prefix+"fileSizeLimit", src.FileSizeLimit, true)
+		validateBuffer(&errs, prefix, src.UseBufferedWriter, src.WriterBufferSize, src.FlushInterval)
+		validateCompress(&errs, prefix+"compress", src.Compress)
+	}
+	if trc := c.TimeRollingFileConfig; trc.Enable {
+		const prefix = "timeRollingFileConfig."
+		validateRequired(&errs, prefix+"logFileBaseName", trc.LogFileBaseName)
+		validateRollingPeriod(&errs, prefix+"rollingPeriod", trc.RollingPeriod)
+		validateBuffer(&errs, prefix, trc.UseBufferedWriter, trc.WriterBufferSize, trc.FlushInterval)
+		validateCompress(&errs, prefix+"compress", trc.Compress)
+	}
+	if hrc := c.HybridRollingFileConfig; hrc.Enable {
+		const prefix = "hybridRollingFileConfig."
+		validateRequired(&errs, prefix+"logFileBaseName", hrc.LogFileBaseName)
+		validateRolling(&errs, prefix, hrc.RollingPeriod, hrc.FileSizeLimit, hrc.MaxAge, hrc.MaxTotalSize, hrc.Compress)
+		validateBuffer(&errs, prefix, hrc.UseBufferedWriter, hrc.WriterBufferSize, hrc.FlushInterval)
+	}
+	if lsc := c.LevelSplitFileConfig; lsc.Enable {
+		const prefix = "levelSplitFileConfig."
+		validateRequired(&errs, prefix+"logFileBaseName", lsc.LogFileBaseName)
+		for i, l := range lsc.Levels {
+			validateLevel(&errs, fmt.Sprintf("%slevels[%d]", prefix, i), l)
+		}
+		validateRolling(&errs, prefix, lsc.RollingPeriod, lsc.FileSizeLimit, lsc.MaxAge, lsc.MaxTotalSize, lsc.Compress)
+		validateBuffer(&errs, prefix, lsc.UseBufferedWriter, lsc.WriterBufferSize, lsc.FlushInterval)
+	}
+	if lrc := c.LabelRouteFileConfig; lrc.Enable {
+		const prefix = "labelRouteFileConfig."
+		for i, route := range lrc.Routes {
+			validateRequired(&errs, fmt.Sprintf("%sroutes[%d].label", prefix, i), route.Label)
+			validateRequired(&errs, fmt.Sprintf("%sroutes[%d].logFileBaseName", prefix, i), route.LogFileBaseName)
+		}
+		validateRolling(&errs, prefix, lrc.RollingPeriod, lrc.FileSizeLimit, lrc.MaxAge, lrc.MaxTotalSize, lrc.Compress)
+		validateBuffer(&errs, prefix, lrc.UseBufferedWriter, lrc.WriterBufferSize, lrc.FlushInterval)
+	}
+	for i, wc := range c.Writers {
+		if !wc.Enable {
+			continue
+		}
+		prefix := fmt.Sprintf("writers[%d].", i)
+		validateRequired(&errs, prefix+"type", wc.Type)
+		if wc.MinLevel != "" {
+			validateLevel(&errs, prefix+"minLevel", wc.MinLevel)
+		}
+		validateBuffer(&errs, prefix, wc.UseBufferedWriter, wc.WriterBufferSize, wc.FlushInterval)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateRequired(errs *ValidationError, field, value string) {
+	if value == "" {
+		errs.Addf(field, "required")
+	}
+}
+
+func validateLevel(errs *ValidationError, field, value string) {
+	if level.FromString(value) == level.None {
+		errs.Addf(field, "unknown level %q", value)
+	}
+}
+
+func validateSize(errs *ValidationError, field, value string, required bool) {
+	if value == "" && !required {
+		return
+	}
+	if _, err := util.ParseToBytesSize(value, 1024); err != nil {
+		errs.Addf(field, "invalid size %q, e.g. '4K' or '100M' expected", value)
+	}
+}
+
+func validateDuration(errs *ValidationError, field, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.ParseDuration(value); err != nil {
+		errs.Addf(field, "invalid duration %q, e.g. '500ms' or '1s' expected", value)
+	}
+}
+
+func validateRollingPeriod(errs *ValidationError, field string, value filewriter.RollingPeriod) {
+	switch value {
+	case filewriter.RollingPeriodYear, filewriter.RollingPeriodMonth, filewriter.RollingPeriodDay,
+		filewriter.RollingPeriodHour, filewriter.RollingPeriodMinute, filewriter.RollingPeriodSecond:
+	default:
+		errs.Addf(field, "unknown rolling period %q, 'YEAR', 'MONTH', 'DAY', 'HOUR', 'MINUTE' or 'SECOND' expected", value)
+	}
+}
+
+// validateCompress checks the compression supported by rainbowlog.ParseCompression.
+func validateCompress(errs *ValidationError, field, value string) {
+	switch strings.ToLower(value) {
+	case "", "none", "gzip", "gz", "zstd", "zst":
+	default:
+		errs.Addf(field, "unsupported compression %q, 'gzip' or 'zstd' expected", value)
+	}
+}
+
+func validateRolling(
+	errs *ValidationError,
+	prefix string,
+	rollingPeriod filewriter.RollingPeriod,
+	fileSizeLimit, maxAge, maxTotalSize, compress string,
+) {
+	validateRollingPeriod(errs, prefix+"rollingPeriod", rollingPeriod)
+	validateSize(errs, prefix+"fileSizeLimit", fileSizeLimit, true)
+	validateDuration(errs, prefix+"maxAge", maxAge)
+	validateSize(errs, prefix+"maxTotalSize", maxTotalSize, false)
+	validateCompress(errs, prefix+"compress", compress)
+}
+
+func validateBuffer(errs *ValidationError, prefix string, useBufferedWriter bool, writerBufferSize, flushInterval string) {
+	if !useBufferedWriter {
+		return
+	}
+	validateSize(errs, prefix+"writerBufferSize", writerBufferSize, true)
+	validateDuration(errs, prefix+"flushInterval", flushInterval)
+}
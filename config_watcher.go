@@ -0,0 +1,126 @@
+package rainbowlog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rambollwong/rainbowlog/config"
+)
+
+// configReloadDelay is the duration to wait for more changes of the config file before reloading,
+// so that a file written in several steps is reloaded once.
+var configReloadDelay = 100 * time.Millisecond
+
+// ConfigWatcher watches a config file and reloads the config to a Logger whenever the file changes.
+type ConfigWatcher struct {
+	configFile string
+	logger     *Logger
+	last       config.LoggerConfig
+
+	watcher   *fsnotify.Watcher
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// WatchConfigFile watches the config file and applies the config to the logger by Logger.ReloadConfig
+// whenever the file changes. The logger is expected to be created from the file, e.g. by NewFromConfigFile,
+// so the config loaded when watching starts is taken as the last good config and is not applied.
+//
+// If the file changed fails to be loaded, validated or applied, the error is reported to ErrorHandler
+// and the logger keeps working with the last good config.
+// Call ConfigWatcher.Close to stop watching.
+func WatchConfigFile(configFile string, logger *Logger) (*ConfigWatcher, error) {
+	if logger == nil {
+		return nil, errors.New("logger is required")
+	}
+	configFile, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadLoggerConfigFromFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("error load logger config from file %s: %w", configFile, err)
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watch the directory, since editors and tools may replace the file by renaming another one
+	if err = watcher.Add(filepath.Dir(configFile)); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	w := &ConfigWatcher{
+		configFile: configFile,
+		logger:     logger,
+		last:       *cfg,
+		watcher:    watcher,
+		done:       make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+func (w *ConfigWatcher) run() {
+	defer w.wg.Done()
+	timer := time.NewTimer(configReloadDelay)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.configFile || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			timer.Reset(configReloadDelay)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			reportError(fmt.Errorf("error watch logger config file %s: %w", w.configFile, err))
+		case <-timer.C:
+			w.reload()
+		}
+	}
+}
+
+// reload loads the config file and applies it to the logger if it differs from the last good config.
+func (w *ConfigWatcher) reload() {
+	cfg, err := config.LoadLoggerConfigFromFile(w.configFile)
+	if err != nil {
+		reportError(fmt.Errorf("error reload logger config from file %s: %w", w.configFile, err))
+		return
+	}
+	if reflect.DeepEqual(*cfg, w.last) {
+		return
+	}
+	if err = w.logger.ReloadConfig(*cfg); err != nil {
+		reportError(fmt.Errorf("error reload logger config from file %s: %w", w.configFile, err))
+		return
+	}
+	w.last = *cfg
+}
+
+// Close stops watching the config file.
+func (w *ConfigWatcher) Close() (err error) {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+		w.wg.Wait()
+	})
+	return err
+}
@@ -0,0 +1,255 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(bz []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(bz)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func reloadTestConfig(dir string) config.LoggerConfig {
+	cfg := config.DefaultLoggerConfig()
+	cfg.EnableConsolePrinting = false
+	cfg.HybridRollingFileConfig.Enable = true
+	cfg.HybridRollingFileConfig.LogFilePath = dir
+	cfg.HybridRollingFileConfig.Encoder = "text"
+	return cfg
+}
+
+// closeLoggerWriters closes writers of the logger when the test finishes,
+// so that background goroutines of the writers are done before other tests.
+func closeLoggerWriters(t *testing.T, logger *Logger) {
+	t.Cleanup(func() {
+		for _, wep := range logger.settings().writerEncoders {
+			closeWriter(wep.writer)
+		}
+	})
+}
+
+// reloadConfig reloads the config of the logger, writers replaced are closed when the test finishes,
+// so that background goroutines of them are done before other tests.
+func reloadConfig(t *testing.T, logger *Logger, cfg config.LoggerConfig) error {
+	replaced := logger.settings().writerEncoders
+	t.Cleanup(func() {
+		for _, wep := range replaced {
+			closeWriter(wep.writer)
+		}
+	})
+	return logger.ReloadConfig(cfg)
+}
+
+func TestLogger_ReloadConfig(t *testing.T) {
+	t.Run("Succeeded", func(t *testing.T) {
+		oldDir, newDir := t.TempDir(), t.TempDir()
+		buf := &syncBuffer{}
+		logger, err := NewFromConfig(
+			reloadTestConfig(oldDir),
+			WithMetaKeys(MetaLabelFieldName, MsgFieldName),
+			AppendsEncoderWriters(TextEnc, buf),
+		)
+		require.NoError(t, err)
+		closeLoggerWriters(t, logger)
+
+		inFlight := logger.Info()
+		cfg := reloadTestConfig(newDir)
+		cfg.Level = "WARN"
+		cfg.Label = "reloaded"
+		require.NoError(t, logger.ReloadConfig(cfg))
+		assert.Equal(t, level.Warn, logger.GetLevel())
+
+		// the record created before reloading is written by the writers replaced
+		inFlight.Msg("before").Done()
+		logger.Info().Msg("ignored").Done()
+		logger.Warn().Msg("after").Done()
+		require.NoError(t, logger.Flush())
+
+		assert.Equal(t, "before\nreloaded > after\n", buf.String())
+		baseName := cfg.HybridRollingFileConfig.LogFileBaseName
+		require.Eventually(t, func() bool {
+			bz, _ := os.ReadFile(filepath.Join(oldDir, baseName))
+			return string(bz) == "before\n"
+		}, time.Second, 10*time.Millisecond)
+		bz, err := os.ReadFile(filepath.Join(newDir, baseName))
+		require.NoError(t, err)
+		assert.Equal(t, "reloaded > after\n", string(bz))
+	})
+
+	t.Run("SubLoggerInheritsReloadedSettings", func(t *testing.T) {
+		oldDir, newDir := t.TempDir(), t.TempDir()
+		logger, err := NewFromConfig(reloadTestConfig(oldDir), WithMetaKeys(MetaLabelFieldName, MsgFieldName))
+		require.NoError(t, err)
+		closeLoggerWriters(t, logger)
+		sub := logger.SubLogger(WithLabels("sub"))
+
+		inFlight := sub.Info()
+		cfg := reloadTestConfig(newDir)
+		cfg.Label = "reloaded"
+		require.NoError(t, reloadConfig(t, logger, cfg))
+		assert.Error(t, sub.ReloadConfig(cfg))
+
+		// the writers replaced are kept open until the record of the sub logger is done
+		time.Sleep(50 * time.Millisecond)
+		inFlight.Msg("before").Done()
+		sub.Info().Msg("after").Done()
+		require.NoError(t, sub.Flush())
+
+		baseName := cfg.HybridRollingFileConfig.LogFileBaseName
+		require.Eventually(t, func() bool {
+			bz, _ := os.ReadFile(filepath.Join(oldDir, baseName))
+			return string(bz) == "sub > before\n"
+		}, time.Second, 10*time.Millisecond)
+		bz, err := os.ReadFile(filepath.Join(newDir, baseName))
+		require.NoError(t, err)
+		assert.Equal(t, "sub > after\n", string(bz))
+	})
+
+	t.Run("KeepLastGoodConfig", func(t *testing.T) {
+		dir := t.TempDir()
+		buf := &syncBuffer{}
+		logger, err := NewFromConfig(reloadTestConfig(dir), WithMetaKeys(), AppendsEncoderWriters(TextEnc, buf))
+		require.NoError(t, err)
+		closeLoggerWriters(t, logger)
+
+		cfg := reloadTestConfig(dir)
+		cfg.Level = "VERBOSE"
+		var errs config.ValidationError
+		require.ErrorAs(t, logger.ReloadConfig(cfg), &errs)
+
+		RegisterWriterFactory("failing", func(map[string]any) (LevelWriter, error) {
+			return nil, os.ErrPermission
+		})
+		cfg = reloadTestConfig(t.TempDir())
+		cfg.Writers = []config.LoggerWriterConfig{{Enable: true, Type: "failing", Encoder: "json"}}
+		require.Error(t, logger.ReloadConfig(cfg))
+
+		assert.Equal(t, level.Debug, logger.GetLevel())
+		logger.Debug().Msg("still works").Done()
+		require.NoError(t, logger.Flush())
+		assert.Equal(t, "message=still works\n", buf.String())
+	})
+	t.Run("KeepExplicitOptions", func(t *testing.T) {
+		buf := &syncBuffer{}
+		hooks := 0
+		logger, err := NewFromConfig(
+			reloadTestConfig(t.TempDir()),
+			WithMetaKeys(MetaTimeFieldName),
+			WithTimeFormat("2006"),
+			WithFieldNames(FieldNames{Message: "msg", Time: "ts"}),
+			WithDurationUseInt(true),
+			WithCallerSkipFrameCount(1),
+			AppendsEncoderWriters(JsonEnc, buf),
+			AppendsHooks(HookFunc(func(r Record, lv level.Level, msg string) { hooks++ })),
+		)
+		require.NoError(t, err)
+		closeLoggerWriters(t, logger)
+
+		cfg := reloadTestConfig(t.TempDir())
+		cfg.TimeFormat = "UNIX"
+		cfg.DurationFormat = "float"
+		cfg.CallerSkipFrameCount = 2
+		require.NoError(t, reloadConfig(t, logger, cfg))
+		require.NoError(t, reloadConfig(t, logger, cfg))
+
+		s := logger.settings()
+		assert.Len(t, s.writerEncoders, 2)
+		assert.Len(t, s.hooks, 1)
+		assert.Equal(t, 1, s.callerSkipFrameCount)
+		logger.Info().Dur("cost", time.Second, 1500*time.Millisecond).Msg("hello").Done()
+		require.NoError(t, logger.Flush())
+		assert.Equal(t, 1, hooks)
+		assert.Equal(t, `{"ts":"`+time.Now().Format("2006")+`","cost":1,"msg":"hello"}`+"\n", buf.String())
+	})
+}
+
+func TestLogger_ReloadConfigConcurrently(t *testing.T) {
+	const goroutines, records = 4, 500
+	buf := &syncBuffer{}
+	logger, err := NewFromConfig(reloadTestConfig(t.TempDir()), WithMetaKeys(), AppendsEncoderWriters(TextEnc, buf))
+	require.NoError(t, err)
+	closeLoggerWriters(t, logger)
+
+	sub := logger.SubLogger()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(l *Logger) {
+			defer wg.Done()
+			for j := 0; j < records; j++ {
+				l.Info().Int("j", j).Done()
+			}
+		}([]*Logger{logger, sub}[i%2])
+	}
+	for i := 0; i < 5; i++ {
+		cfg := reloadTestConfig(t.TempDir())
+		cfg.Label = "reload" + strconv.Itoa(i)
+		require.NoError(t, reloadConfig(t, logger, cfg))
+	}
+	wg.Wait()
+	require.NoError(t, logger.Flush())
+	assert.Equal(t, goroutines*records, strings.Count(buf.String(), "\n"))
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	errCh := make(chan error, 10)
+	errorHandler := ErrorHandler
+	defer func() { ErrorHandler = errorHandler }()
+	ErrorHandler = func(err error) { errCh <- err }
+
+	cfgFile := filepath.Join(t.TempDir(), "rainbowlog.yaml")
+	cfg := reloadTestConfig(t.TempDir())
+	require.NoError(t, config.WriteConfigToFile(cfgFile, cfg))
+	logger, err := NewFromConfigFile(cfgFile)
+	require.NoError(t, err)
+	closeLoggerWriters(t, logger)
+	watcher, err := WatchConfigFile(cfgFile, logger)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, watcher.Close()) }()
+
+	cfg.Level = "WARN"
+	require.NoError(t, config.WriteConfigToFile(cfgFile, cfg))
+	require.Eventually(t, func() bool {
+		return logger.GetLevel() == level.Warn
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cfg.Level = "VERBOSE"
+	require.NoError(t, config.WriteConfigToFile(cfgFile, cfg))
+	timeout := time.After(5 * time.Second)
+	for reported := false; !reported; {
+		select {
+		case err := <-errCh:
+			// writers of other tests may report errors too
+			reported = strings.Contains(err.Error(), `level: unknown level "VERBOSE"`)
+		case <-timeout:
+			t.Fatal("reloading invalid config is not reported")
+		}
+	}
+	assert.Equal(t, level.Warn, logger.GetLevel())
+}
@@ -0,0 +1,118 @@
+package rainbowlog
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// errorChainMaxLength is the maximum number of errors collected from the chain of an error.
+const errorChainMaxLength = 32
+
+// ErrorInfo describes an error in the chain of a wrapped error,
+// which is printed as {"msg":"","type":"","stack":[...]} with the stack omitted if none.
+type ErrorInfo struct {
+	Msg   string       `json:"msg"`
+	Type  string       `json:"type"`
+	Stack []StackFrame `json:"stack,omitempty"`
+
+	depth int // depth of the error in the tree of errors joined, 0 for the error logged
+}
+
+// ErrorChain is the chain of a wrapped error from the outermost one,
+// errors joined are listed depth-first after the one joining them.
+// It is printed as an array of ErrorInfo, and as an indented tree on console.
+type ErrorChain []ErrorInfo
+
+var (
+	// ChainErrorStackMarshalFunc marshals the err into an ErrorChain by walking errors.Unwrap and errors.Join chains,
+	// and pkg/errors style Cause chains. Stacks are extracted from errors implementing one of:
+	//
+	//	StackTrace() T          // T is a slice of program counters, e.g. errors.StackTrace of pkg/errors
+	//	Callers() []uintptr
+	//	Frames() *runtime.Frames
+	//
+	// It returns nil if no error in the chain carries a stack, so that nothing is printed for plain errors.
+	ChainErrorStackMarshalFunc ErrorStackMarshalFunc = func(err error) any {
+		chain := errorChainOf(err)
+		for _, info := range chain {
+			if len(info.Stack) > 0 {
+				return chain
+			}
+		}
+		return nil
+	}
+
+	// FramesErrorStackMarshalFunc marshals the err into the []StackFrame of the innermost error carrying a stack
+	// in the chain, which is where the error was created usually. It returns nil if no stack found.
+	FramesErrorStackMarshalFunc ErrorStackMarshalFunc = func(err error) any {
+		var frames []StackFrame
+		depth := -1
+		for _, info := range errorChainOf(err) {
+			if len(info.Stack) > 0 && info.depth > depth {
+				frames, depth = info.Stack, info.depth
+			}
+		}
+		if frames == nil {
+			return nil
+		}
+		return frames
+	}
+)
+
+// errorChainOf walks the chain of err depth-first.
+func errorChainOf(err error) ErrorChain {
+	var chain ErrorChain
+	var walk func(err error, depth int)
+	walk = func(err error, depth int) {
+		if err == nil || len(chain) >= errorChainMaxLength {
+			return
+		}
+		chain = append(chain, ErrorInfo{
+			Msg:   err.Error(),
+			Type:  fmt.Sprintf("%T", err),
+			Stack: errorStackOf(err),
+			depth: depth,
+		})
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap(), depth+1)
+		case interface{ Unwrap() []error }:
+			for _, ee := range e.Unwrap() {
+				walk(ee, depth+1)
+			}
+		case interface{ Cause() error }:
+			walk(e.Cause(), depth+1)
+		}
+	}
+	walk(err, 0)
+	return chain
+}
+
+// errorStackOf extracts the stack carried by the err itself, nil if none.
+func errorStackOf(err error) []StackFrame {
+	switch e := err.(type) {
+	case interface{ Callers() []uintptr }:
+		return stackFrames(runtime.CallersFrames(e.Callers()))
+	case interface{ Frames() *runtime.Frames }:
+		if frames := e.Frames(); frames != nil {
+			return stackFrames(frames)
+		}
+		return nil
+	}
+	// the type of StackTrace result is defined by the package of the err, e.g. errors.StackTrace of pkg/errors,
+	// which is a slice of program counters
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	if t := m.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return stackFrames(runtime.CallersFrames(pcs))
+}
@@ -0,0 +1,131 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callersError carries a stack by Callers, like go-errors.
+type callersError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newCallersError(msg string) error {
+	pcs := make([]uintptr, 8)
+	return &callersError{msg: msg, pcs: pcs[:runtime.Callers(2, pcs)]}
+}
+
+func (e *callersError) Error() string      { return e.msg }
+func (e *callersError) Callers() []uintptr { return e.pcs }
+
+// pkgFrame and pkgStackTrace imitate errors.Frame and errors.StackTrace of pkg/errors.
+type (
+	pkgFrame      uintptr
+	pkgStackTrace []pkgFrame
+)
+
+// pkgError carries a stack by StackTrace and wraps a cause, like pkg/errors.
+type pkgError struct {
+	cause error
+	stack pkgStackTrace
+}
+
+func wrapPkgError(cause error) error {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(2, pcs)
+	stack := make(pkgStackTrace, n)
+	for i := range stack {
+		stack[i] = pkgFrame(pcs[i])
+	}
+	return &pkgError{cause: cause, stack: stack}
+}
+
+func (e *pkgError) Error() string             { return e.cause.Error() }
+func (e *pkgError) Cause() error              { return e.cause }
+func (e *pkgError) StackTrace() pkgStackTrace { return e.stack }
+
+func TestChainErrorStackMarshalFunc(t *testing.T) {
+	t.Run("PlainErrors", func(t *testing.T) {
+		err := fmt.Errorf("load: %w", errors.New("not found"))
+		assert.Nil(t, ChainErrorStackMarshalFunc(err))
+		assert.Nil(t, FramesErrorStackMarshalFunc(err))
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		origin := newCallersError("not found")
+		err := fmt.Errorf("load: %w", errors.Join(wrapPkgError(origin), errors.New("timeout")))
+
+		chain, ok := ChainErrorStackMarshalFunc(err).(ErrorChain)
+		require.True(t, ok)
+		require.Len(t, chain, 5)
+		for i, expected := range []struct {
+			typ   string
+			depth int
+			stack bool
+		}{
+			{"*fmt.wrapError", 0, false},
+			{"*errors.joinError", 1, false},
+			{"*rainbowlog.pkgError", 2, true},
+			{"*rainbowlog.callersError", 3, true},
+			{"*errors.errorString", 2, false},
+		} {
+			assert.Equal(t, expected.typ, chain[i].Type)
+			assert.Equal(t, expected.depth, chain[i].depth, expected.typ)
+			assert.Equal(t, expected.stack, len(chain[i].Stack) > 0, expected.typ)
+		}
+		assert.Equal(t, "load: not found\ntimeout", chain[0].Msg)
+		assert.Equal(t, "github.com/rambollwong/rainbowlog.TestChainErrorStackMarshalFunc.func2", chain[2].Stack[0].Func)
+
+		frames, ok := FramesErrorStackMarshalFunc(err).([]StackFrame)
+		require.True(t, ok)
+		assert.Equal(t, chain[3].Stack, frames)
+	})
+
+	t.Run("Json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), WithStack(true), AppendsEncoderWriters(JsonEnc, buf))
+
+		logger.Error().Err(fmt.Errorf("load: %w", newCallersError("not found"))).Done()
+		var record struct {
+			Error string `json:"error"`
+			Stack []struct {
+				Msg   string       `json:"msg"`
+				Type  string       `json:"type"`
+				Stack []StackFrame `json:"stack"`
+			} `json:"stack"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "load: not found", record.Error)
+		require.Len(t, record.Stack, 2)
+		assert.Equal(t, "*fmt.wrapError", record.Stack[0].Type)
+		assert.Empty(t, record.Stack[0].Stack)
+		assert.Equal(t, "not found", record.Stack[1].Msg)
+		require.NotEmpty(t, record.Stack[1].Stack)
+		assert.Equal(t, "github.com/rambollwong/rainbowlog.TestChainErrorStackMarshalFunc.func3", record.Stack[1].Stack[0].Func)
+	})
+
+	t.Run("ConsoleTree", func(t *testing.T) {
+		logger := New(WithMetaKeys(), WithStack(true), WithConsolePrint(true), WithRainbowConsole(false))
+		r := logger.Error().(*LogRecord)
+		buf := &bytes.Buffer{}
+		r.recordPackers[0].(*ConsolePacker).writerEncoderPair.writer = LevelWriterAdapter(buf)
+
+		r.Err(fmt.Errorf("load: %w", newCallersError("not found"))).Done()
+		lines := strings.Split(buf.String(), "\n")
+		require.Greater(t, len(lines), 5)
+		assert.Equal(t, "error=load: not found stack=", lines[0])
+		assert.Equal(t, "    *fmt.wrapError: load: not found", lines[1])
+		assert.Equal(t, "        *rainbowlog.callersError: not found", lines[2])
+		assert.Equal(t, "            github.com/rambollwong/rainbowlog.TestChainErrorStackMarshalFunc.func4()", lines[3])
+		assert.Regexp(t, `^                .+/error_stack_test\.go:\d+$`, lines[4])
+	})
+}
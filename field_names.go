@@ -0,0 +1,71 @@
+package rainbowlog
+
+// FieldNames defines the names of fields printed by a Logger,
+// so that loggers in a binary can print records of different schemas.
+type FieldNames struct {
+	// Message is the field name for core message.
+	Message string
+	// Error is the field name for err.
+	Error string
+	// ErrorStack is the field name for err stack.
+	ErrorStack string
+	// Stack is the field name for the stack added by Record.Stack.
+	Stack string
+	// Time is the meta key field name for time.
+	Time string
+	// Level is the meta key field name for level.
+	Level string
+	// Label is the meta key field name for label.
+	Label string
+	// Caller is the meta key field name for caller.
+	Caller string
+	// Func is the meta key field name for the function name of caller.
+	Func string
+}
+
+// DefaultFieldNames returns the field names defined by the global variables,
+// e.g. MsgFieldName and MetaTimeFieldName, which are used by loggers without WithFieldNames.
+func DefaultFieldNames() FieldNames {
+	return FieldNames{
+		Message:    MsgFieldName,
+		Error:      ErrFieldName,
+		ErrorStack: ErrStackFieldName,
+		Stack:      StackFieldName,
+		Time:       MetaTimeFieldName,
+		Level:      MetaLevelFieldName,
+		Label:      MetaLabelFieldName,
+		Caller:     MetaCallerFieldName,
+		Func:       MetaFuncFieldName,
+	}
+}
+
+// FieldNames returns the field names of the logger, which may be changed by WithFieldNames or config.
+func (l *Logger) FieldNames() FieldNames {
+	return l.settings().fieldNames
+}
+
+// MetaKeys returns a copy of the meta keys of the logger, which may be changed by WithMetaKeys or config.
+func (l *Logger) MetaKeys() []string {
+	return append([]string(nil), l.settings().metaKeys.Keys()...)
+}
+
+// setFieldNames sets the field names of the logger, empty names are left unchanged.
+// Meta keys and console colors of the logger are renamed too.
+func (l *Logger) setFieldNames(names FieldNames) {
+	rename := func(field *string, name string) {
+		if name == "" || name == *field {
+			return
+		}
+		l.metaKeys.Rename(*field, name)
+		*field = name
+	}
+	rename(&l.fieldNames.Message, names.Message)
+	rename(&l.fieldNames.Error, names.Error)
+	rename(&l.fieldNames.ErrorStack, names.ErrorStack)
+	rename(&l.fieldNames.Stack, names.Stack)
+	rename(&l.fieldNames.Time, names.Time)
+	rename(&l.fieldNames.Level, names.Level)
+	rename(&l.fieldNames.Label, names.Label)
+	rename(&l.fieldNames.Caller, names.Caller)
+	rename(&l.fieldNames.Func, names.Func)
+}
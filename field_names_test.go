@@ -0,0 +1,67 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFieldNames(t *testing.T) {
+	names := FieldNames{Message: "msg", Error: "err", Time: "ts", Level: "lvl", Caller: "src"}
+
+	t.Run("JsonEncoder", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithDefault(), WithFieldNames(names), AppendsEncoderWriters(JsonEnc, buf))
+
+		logger.Info().Err(errors.New("boom")).Msg("hello").Done()
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "hello", record["msg"])
+		assert.Equal(t, "boom", record["err"])
+		assert.Equal(t, "INFO", record["lvl"])
+		assert.Contains(t, record, "ts")
+		assert.Regexp(t, `field_names_test\.go:\d+$`, record["src"])
+		assert.NotContains(t, record, MsgFieldName)
+		assert.NotContains(t, record, MetaLevelFieldName)
+		assert.Equal(t, []int{ColorHlBlue}, logger.metaKeys.ConsoleColors("msg"))
+		assert.Equal(t, []int{ColorRed}, logger.metaKeys.ConsoleColors("err"))
+	})
+
+	t.Run("TextEncoder", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(
+			WithMetaKeys(MetaLevelFieldName, MsgFieldName),
+			WithFieldNames(names),
+			AppendsEncoderWriters(TextEnc, buf),
+		)
+
+		logger.Info().Str("user", "alice").Msg("hello").Done()
+		assert.Equal(t, "INFO > user=alice hello\n", buf.String())
+	})
+
+	t.Run("InheritedBySubLogger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), WithFieldNames(names), AppendsEncoderWriters(JsonEnc, buf))
+		sub := logger.SubLogger(WithFieldNames(FieldNames{Error: "cause"}))
+
+		sub.Info().Err(errors.New("boom")).Msg("hello").Done()
+		assert.Equal(t, `{"cause":"boom","msg":"hello"}`+"\n", buf.String())
+	})
+
+	t.Run("IndependentSchemas", func(t *testing.T) {
+		buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+		logger1 := New(WithMetaKeys(), WithFieldNames(FieldNames{Message: "msg"}), AppendsEncoderWriters(JsonEnc, buf1))
+		logger2 := New(WithMetaKeys(), AppendsEncoderWriters(JsonEnc, buf2))
+
+		logger1.Info().Msg("hello").Done()
+		logger2.Info().Msg("hello").Done()
+		assert.Equal(t, `{"msg":"hello"}`+"\n", buf1.String())
+		assert.Equal(t, `{"message":"hello"}`+"\n", buf2.String())
+		assert.Equal(t, "msg", logger1.FieldNames().Message)
+		assert.Equal(t, DefaultFieldNames(), logger2.FieldNames())
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rambollwong/rainbowlog/level"
@@ -45,17 +46,23 @@ const (
 /////////////////////
 
 var (
+	// Field names below are the defaults of FieldNames used by loggers without WithFieldNames,
+	// changing them affects loggers created after only.
+
 	// MsgFieldName is the field name for core message.
 	MsgFieldName = "message"
 	// ErrFieldName is the field name for err.
 	ErrFieldName = "error"
 	// ErrStackFieldName is the field name for err stack.
 	ErrStackFieldName = "stack"
+	// StackFieldName is the field name for the stack added by Record.Stack.
+	StackFieldName = "stacktrace"
 
 	MetaTimeFieldName   = "_TIME_"
 	MetaCallerFieldName = "_CALLER_"
 	MetaLevelFieldName  = "_LEVEL_"
 	MetaLabelFieldName  = "_LABEL_"
+	MetaFuncFieldName   = "_FUNC_"
 
 	// extra meta keys field name for console printing color.
 
@@ -100,9 +107,7 @@ var (
 	CallerSkipFrameCount = 0
 
 	// GlobalCallerMarshalFunc allows customization of global caller marshaling.
-	GlobalCallerMarshalFunc CallerMarshalFunc = func(file string, line int) string {
-		return file + ":" + strconv.Itoa(line)
-	}
+	GlobalCallerMarshalFunc = FullCallerMarshalFunc
 
 	// GlobalErrorMarshalFunc allows customization of global error marshaling.
 	GlobalErrorMarshalFunc ErrorMarshalFunc = func(err error) string {
@@ -112,10 +117,8 @@ var (
 		return err.Error()
 	}
 
-	// GlobalErrorStackMarshalFunc extract the stack from err if any.
-	GlobalErrorStackMarshalFunc ErrorStackMarshalFunc = func(err error) any {
-		return nil
-	}
+	// GlobalErrorStackMarshalFunc extract the stack from err if any, which works if stack enabled by WithStack.
+	GlobalErrorStackMarshalFunc = ChainErrorStackMarshalFunc
 
 	TimestampFunc = func() time.Time {
 		return time.Now()
@@ -188,6 +191,36 @@ const (
 	ColorHlBgPale
 )
 
+// colorNames maps the names of colors in lower case to ANSI codes.
+var colorNames = map[string]int{
+	"bold": ColorBold, "underline": ColorUnderline, "strikethrough": ColorStrikeThrough, "underlinebold": ColorUnderlineBold,
+
+	"black": ColorBlack, "red": ColorRed, "green": ColorGreen, "yellow": ColorYellow,
+	"blue": ColorBlue, "magenta": ColorMagenta, "cyan": ColorCyan, "pale": ColorPale,
+
+	"bgblack": ColorBgBlack, "bgred": ColorBgRed, "bggreen": ColorBgGreen, "bgyellow": ColorBgYellow,
+	"bgblue": ColorBgBlue, "bgmagenta": ColorBgMagenta, "bgcyan": ColorBgCyan, "bgpale": ColorBgPale,
+
+	"hlblack": ColorHlBlack, "hlred": ColorHlRed, "hlgreen": ColorHlGreen, "hlyellow": ColorHlYellow,
+	"hlblue": ColorHlBlue, "hlmagenta": ColorHlMagenta, "hlcyan": ColorHlCyan, "hlpale": ColorHlPale,
+
+	"hlbgblack": ColorHlBgBlack, "hlbgred": ColorHlBgRed, "hlbggreen": ColorHlBgGreen, "hlbgyellow": ColorHlBgYellow,
+	"hlbgblue": ColorHlBgBlue, "hlbgmagenta": ColorHlBgMagenta, "hlbgcyan": ColorHlBgCyan, "hlbgpale": ColorHlBgPale,
+}
+
+// ParseColor parses the name of a color into the ANSI code, e.g. "red", "hlBlue", "bgGreen" or "bold".
+// Names are the names of the Color constants without the "Color" prefix in any case,
+// and numbers are taken as ANSI codes directly.
+func ParseColor(name string) (int, error) {
+	if c, ok := colorNames[strings.ToLower(name)]; ok {
+		return c, nil
+	}
+	if c, err := strconv.Atoi(name); err == nil && c >= 0 {
+		return c, nil
+	}
+	return 0, fmt.Errorf("unknown color: %s", name)
+}
+
 /////////////////////////////
 /// Default logger params ///
 /////////////////////////////
@@ -197,18 +230,62 @@ const (
 	DefaultLabel        = ""
 	DefaultConsolePrint = false
 	DefaultConsoleColor = true
+	DefaultStackLevel   = level.Disabled
 )
 
+// customLevelColorKeys caches console color keys of custom levels, level.Level -> [3]string{name, levelKey, lineKey}.
+var customLevelColorKeys sync.Map
+
+// levelColorKeys returns the console color keys of the level,
+// levelKey for printing the level field and lineKey for printing the first meta field.
+// Keys of custom levels are MetaLevelFieldName + "_" + upper case name (+ "_LINE"), same as built-in levels.
+func levelColorKeys(lv level.Level) (levelKey, lineKey string) {
+	switch lv {
+	case level.Debug:
+		return metaKeysColorLevelDebugFieldName, metaKeysColorLevelDebugLineFieldName
+	case level.Info:
+		return metaKeysColorLevelInfoFieldName, metaKeysColorLevelInfoLineFieldName
+	case level.Warn:
+		return metaKeysColorLevelWarnFieldName, metaKeysColorLevelWarnLineFieldName
+	case level.Error:
+		return metaKeysColorLevelErrorFieldName, metaKeysColorLevelErrorLineFieldName
+	case level.Fatal:
+		return metaKeysColorLevelFatalFieldName, metaKeysColorLevelFatalLineFieldName
+	case level.Panic:
+		return metaKeysColorLevelPanicFieldName, metaKeysColorLevelPanicLineFieldName
+	case level.Trace:
+		return metaKeysColorLevelTraceFieldName, metaKeysColorLevelTraceLineFieldName
+	}
+	name := lv.String()
+	if v, ok := customLevelColorKeys.Load(lv); ok {
+		// the name changes if the level is registered after being cached
+		if keys := v.([3]string); keys[0] == name {
+			return keys[1], keys[2]
+		}
+	}
+	levelKey = MetaLevelFieldName + "_" + strings.ToUpper(name)
+	lineKey = levelKey + "_LINE"
+	customLevelColorKeys.Store(lv, [3]string{name, levelKey, lineKey})
+	return levelKey, lineKey
+}
+
 func defaultMetaKeys() *metaKeys {
+	return defaultMetaKeysOf(DefaultFieldNames())
+}
+
+// defaultMetaKeysOf returns the default meta keys and console colors with the field names given.
+func defaultMetaKeysOf(names FieldNames) *metaKeys {
 	return &metaKeys{
-		keys: []string{MetaTimeFieldName, MetaLevelFieldName, MetaLabelFieldName, MetaCallerFieldName, MsgFieldName},
+		keys: []string{names.Time, names.Level, names.Label, names.Caller, names.Message},
 		consoleColors: map[string][]int{
-			MetaTimeFieldName:   {ColorPale},
-			MetaLevelFieldName:  {},
-			MetaLabelFieldName:  {ColorHlMagenta},
-			MetaCallerFieldName: {ColorCyan},
-			MsgFieldName:        {ColorHlBlue},
-			ErrFieldName:        {ColorRed},
+			names.Time:    {ColorPale},
+			names.Level:   {},
+			names.Label:   {ColorHlMagenta},
+			names.Caller:  {ColorCyan},
+			names.Func:    {ColorCyan},
+			names.Message: {ColorHlBlue},
+			names.Error:   {ColorRed},
+			names.Stack:   {ColorHlBlack},
 
 			metaKeysColorLevelDebugFieldName: {ColorMagenta},
 			metaKeysColorLevelInfoFieldName:  {ColorGreen},
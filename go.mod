@@ -3,6 +3,9 @@ module github.com/rambollwong/rainbowlog
 go 1.23
 
 require (
+	github.com/fsnotify/fsnotify v1.6.0
+	github.com/klauspost/compress v1.17.9
+	github.com/mitchellh/mapstructure v1.5.0
 	github.com/rambollwong/rainbowcat v0.0.0-20250206043332-9b571afe68ca
 	github.com/spf13/viper v1.17.0
 	github.com/stretchr/testify v1.10.0
@@ -10,10 +13,8 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
-	github.com/fsnotify/fsnotify v1.6.0 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/magiconair/properties v1.8.7 // indirect
-	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/pelletier/go-toml/v2 v2.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/sagikazarmark/locafero v0.3.0 // indirect
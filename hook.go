@@ -19,6 +19,8 @@ func (hf HookFunc) RunHook(r Record, level level.Level, message string) {
 // LevelHook applies a different hook for each level.
 type LevelHook struct {
 	NoneLevelHook, TraceHook, DebugHook, InfoHook, WarnHook, ErrorHook, FatalHook, PanicHook Hook
+	// CustomHooks applies hooks for custom levels registered by level.Register.
+	CustomHooks map[level.Level]Hook
 }
 
 // RunHook implements the Hook interface.
@@ -56,6 +58,10 @@ func (lh LevelHook) RunHook(r Record, lv level.Level, message string) {
 		if lh.NoneLevelHook != nil {
 			lh.NoneLevelHook.RunHook(r, lv, message)
 		}
+	default:
+		if h := lh.CustomHooks[lv]; h != nil {
+			h.RunHook(r, lv, message)
+		}
 	}
 }
 
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // Level of log.
@@ -52,44 +54,116 @@ const (
 	NoneStr = "unknown"
 )
 
+// registry holds names of levels. It is replaced as a whole when a level is registered,
+// so that reading names needs no lock.
+type registry struct {
+	ls  map[Level]string // level -> name
+	lkv map[Level]string // level -> short name
+	sl  map[string]Level // name -> level
+}
+
 var (
-	_lsM = map[Level]string{
-		Disabled: DisabledStr,
-		Debug:    DebugStr,
-		Info:     InfoStr,
-		Warn:     WarnStr,
-		Error:    ErrorStr,
-		Fatal:    FatalStr,
-		Panic:    PanicStr,
-		Trace:    TraceStr,
-		None:     NoneStr,
+	_registry atomic.Pointer[registry]
+	_regMu    sync.Mutex
+)
+
+func init() {
+	_registry.Store(&registry{
+		ls: map[Level]string{
+			Disabled: DisabledStr,
+			Debug:    DebugStr,
+			Info:     InfoStr,
+			Warn:     WarnStr,
+			Error:    ErrorStr,
+			Fatal:    FatalStr,
+			Panic:    PanicStr,
+			Trace:    TraceStr,
+			None:     NoneStr,
+		},
+		lkv: map[Level]string{
+			Disabled: "DIS",
+			Debug:    "DEB",
+			Info:     "INF",
+			Warn:     "WAR",
+			Error:    "ERR",
+			Fatal:    "FAT",
+			Panic:    "PAN",
+			Trace:    "TRA",
+			None:     "UNK",
+		},
+		sl: map[string]Level{
+			DisabledStr: Disabled,
+			DebugStr:    Debug,
+			InfoStr:     Info,
+			WarnStr:     Warn,
+			ErrorStr:    Error,
+			FatalStr:    Fatal,
+			PanicStr:    Panic,
+			TraceStr:    Trace,
+			NoneStr:     None,
+		},
+	})
+}
+
+// Register registers a custom level with its name and short name, e.g. Register(8, "audit", "AUD").
+// The name is case-insensitive and stored in lower case like names of built-in levels,
+// so that the level can be parsed by ParseLevel and printed by String.
+// The short name is printed by KeyFieldValue, which is 3 upper case letters for built-in levels.
+//
+// Custom levels are ordered by value together with built-in levels,
+// a record is printed if its level is greater than or equal to the level of the logger.
+// Built-in levels take values from -1 (Trace) to 7 (Disabled),
+// so a custom level greater than 7 ranks above Panic and a custom level less than -1 ranks below Trace.
+//
+// Register should be called before logging, e.g. in an init function.
+// An error is returned if the value or the name has been registered, or the name is empty or numeric.
+func Register(value Level, name, shortName string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || shortName == "" {
+		return fmt.Errorf("name and short name of level %d should not be empty", value)
 	}
-	_lkvM = map[Level]string{
-		Disabled: "DIS",
-		Debug:    "DEB",
-		Info:     "INF",
-		Warn:     "WAR",
-		Error:    "ERR",
-		Fatal:    "FAT",
-		Panic:    "PAN",
-		Trace:    "TRA",
-		None:     "UNK",
+	if _, err := strconv.Atoi(name); err == nil {
+		return fmt.Errorf("name of level %d should not be numeric: %s", value, name)
 	}
-	_slM = map[string]Level{
-		DisabledStr: Disabled,
-		DebugStr:    Debug,
-		InfoStr:     Info,
-		WarnStr:     Warn,
-		ErrorStr:    Error,
-		FatalStr:    Fatal,
-		PanicStr:    Panic,
-		TraceStr:    Trace,
-		NoneStr:     None,
+
+	_regMu.Lock()
+	defer _regMu.Unlock()
+	old := _registry.Load()
+	if exist, ok := old.ls[value]; ok {
+		return fmt.Errorf("level %d has been registered as %s", value, exist)
 	}
-)
+	if exist, ok := old.sl[name]; ok {
+		return fmt.Errorf("level name %s has been registered by level %d", name, exist)
+	}
+	reg := &registry{
+		ls:  make(map[Level]string, len(old.ls)+1),
+		lkv: make(map[Level]string, len(old.lkv)+1),
+		sl:  make(map[string]Level, len(old.sl)+1),
+	}
+	for l, s := range old.ls {
+		reg.ls[l] = s
+	}
+	for l, s := range old.lkv {
+		reg.lkv[l] = s
+	}
+	for s, l := range old.sl {
+		reg.sl[s] = l
+	}
+	reg.ls[value] = name
+	reg.lkv[value] = shortName
+	reg.sl[name] = value
+	_registry.Store(reg)
+	return nil
+}
+
+// IsRegistered returns whether the level is a built-in level or has been registered.
+func IsRegistered(l Level) bool {
+	_, ok := _registry.Load().ls[l]
+	return ok
+}
 
 func (l Level) String() string {
-	str, ok := _lsM[l]
+	str, ok := _registry.Load().ls[l]
 	if ok {
 		return str
 	}
@@ -97,7 +171,7 @@ func (l Level) String() string {
 }
 
 func (l Level) KeyFieldValue() string {
-	str, ok := _lkvM[l]
+	str, ok := _registry.Load().lkv[l]
 	if ok {
 		return str
 	}
@@ -105,7 +179,7 @@ func (l Level) KeyFieldValue() string {
 }
 
 func FromString(levelStr string) Level {
-	l, ok := _slM[strings.ToLower(levelStr)]
+	l, ok := _registry.Load().sl[strings.ToLower(levelStr)]
 	if ok {
 		return l
 	}
@@ -111,3 +111,38 @@ func TestParseLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestRegister(t *testing.T) {
+	const notice, verbose Level = 20, -20
+	require.NoError(t, Register(notice, "NOTICE", "NOT"))
+	require.NoError(t, Register(verbose, "verbose", "VER"))
+
+	require.Equal(t, "notice", notice.String())
+	require.Equal(t, "NOT", notice.KeyFieldValue())
+	require.Equal(t, notice, FromString("Notice"))
+	l, err := ParseLevel("verbose")
+	require.NoError(t, err)
+	require.Equal(t, verbose, l)
+	require.True(t, IsRegistered(notice))
+	require.True(t, IsRegistered(Info))
+	require.False(t, IsRegistered(Level(21)))
+
+	tests := []struct {
+		value     Level
+		name      string
+		shortName string
+	}{
+		{Info, "information", "INF"},
+		{notice, "notice2", "NO2"},
+		{Level(21), "warn", "WAR"},
+		{Level(21), "notice", "NOT"},
+		{Level(21), "", "EMP"},
+		{Level(21), "empty", ""},
+		{Level(21), "21", "NUM"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("register level %d as %q", test.value, test.name), func(t *testing.T) {
+			require.Error(t, Register(test.value, test.name, test.shortName))
+		})
+	}
+}
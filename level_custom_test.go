@@ -0,0 +1,66 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomLevel(t *testing.T) {
+	const audit level.Level = 30
+	require.NoError(t, level.Register(audit, "audit", "AUD"))
+
+	t.Run("MarshalAndOrder", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(
+			AppendsEncoderWriters(JsonEnc, buf),
+			WithMetaKeys(MetaLevelFieldName, MsgFieldName),
+			WithLevel(level.Panic),
+		)
+		logger.Level(audit).Msg("login").Done()
+		logger.Error().Msg("ignored").Done()
+		logger.Level(audit).Discard().Msg("discarded").Done()
+		assert.Equal(t, "{\"_LEVEL_\":\"AUDIT\",\"message\":\"login\"}\n", buf.String())
+	})
+
+	t.Run("LevelHook", func(t *testing.T) {
+		var messages []string
+		hook := NewLevelHook()
+		hook.CustomHooks = map[level.Level]Hook{
+			audit: HookFunc(func(r Record, lv level.Level, message string) {
+				messages = append(messages, lv.String()+":"+message)
+			}),
+		}
+		logger := New(AppendsEncoderWriters(JsonEnc, &bytes.Buffer{}), AppendsHooks(hook))
+		logger.Level(audit).Msg("login").Done()
+		logger.Info().Msg("started").Done()
+		assert.Equal(t, []string{"audit:login"}, messages)
+	})
+
+	t.Run("ConsoleColors", func(t *testing.T) {
+		logger := New(
+			WithConsolePrint(true),
+			WithRainbowConsole(true),
+			WithMetaKeys(MetaLabelFieldName, MetaLevelFieldName, MsgFieldName),
+			WithLabels("app"),
+			WithLevelColors(audit, ColorCyan),
+			WithLevelLineColors(audit, ColorBgCyan),
+		)
+		buf := &bytes.Buffer{}
+		r := logger.Level(audit).(*LogRecord)
+		for _, rp := range r.recordPackers {
+			if cp, ok := rp.(*ConsolePacker); ok {
+				cp.writerEncoderPair.writer = LevelWriterAdapter(buf)
+			}
+		}
+		r.Msg("login").Done()
+
+		out := buf.String()
+		assert.True(t, strings.Contains(out, string(colorStart(nil, ColorBgCyan))), out)
+		assert.True(t, strings.Contains(out, string(colorStart(nil, ColorCyan))+" AUD"), out)
+	})
+}
@@ -2,10 +2,14 @@
 package log
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 
 	"github.com/rambollwong/rainbowlog"
+	"github.com/rambollwong/rainbowlog/config"
 	"github.com/rambollwong/rainbowlog/level"
 )
 
@@ -78,15 +82,53 @@ func UseCustomOptions(opts ...rainbowlog.Option) {
 }
 
 // UseDefaultConfigFile allows user to initial the global Logger by global config file and custom options.
+// Environment variables of config.DefaultEnvPrefix overlay the config file, e.g. RAINBOWLOG_LEVEL,
+// and if the config file does not exist, they overlay the default config instead,
+// so the precedence is defaults < config file < environment variables < custom options.
+// If the config fails to be loaded or is invalid, trigger a panic.
 func UseDefaultConfigFile(opts ...rainbowlog.Option) {
+	cfg, err := loadDefaultConfig()
+	if err != nil {
+		panic(err.Error())
+	}
 	Logger = rainbowlog.New(append(
 		[]rainbowlog.Option{
 			rainbowlog.WithDefault(),
-			rainbowlog.WithConfigFile(filepath.Join(DefaultConfigFilePath, DefaultConfigFileName)),
+			rainbowlog.WithConfig(*cfg),
 		}, opts...)...,
 	)
 }
 
+// UseDefaultConfigFileE is like UseDefaultConfigFile, but returns an error instead of triggering a panic
+// if the config file fails to be loaded, the config is invalid or any writer fails to be created.
+// The global Logger is not changed if an error is returned.
+func UseDefaultConfigFileE(opts ...rainbowlog.Option) error {
+	cfg, err := loadDefaultConfig()
+	if err != nil {
+		return err
+	}
+	logger, err := rainbowlog.NewFromConfig(*cfg, opts...)
+	if err != nil {
+		return err
+	}
+	Logger = logger
+	return nil
+}
+
+// loadDefaultConfig loads the global config file overlaid with environment variables,
+// or the default config overlaid with environment variables if the file does not exist.
+func loadDefaultConfig() (*config.LoggerConfig, error) {
+	configFile := filepath.Join(DefaultConfigFilePath, DefaultConfigFileName)
+	if _, err := os.Stat(configFile); errors.Is(err, fs.ErrNotExist) {
+		return config.LoadFromEnv(config.DefaultEnvPrefix)
+	}
+	cfg, err := config.LoadLoggerConfigFromFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("error load logger config from file %s: %w", configFile, err)
+	}
+	return cfg, nil
+}
+
 // Record create a new rainbowlog.Record with basic.
 func Record() rainbowlog.Record {
 	return Logger.Record()
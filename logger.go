@@ -6,7 +6,9 @@ package rainbowlog
 
 import (
 	"os"
+	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/rambollwong/rainbowlog/internal/encoder"
 	"github.com/rambollwong/rainbowlog/level"
@@ -16,17 +18,23 @@ import (
 type WriterEncoderPair struct {
 	writer LevelWriter
 	enc    Encoder
+	// fromConfig marks the writer created by config, which is replaced when the config reloaded.
+	fromConfig bool
 }
 
 // Logger is the rainbow-log structure.
 // This is the main entrance of rainbow log.
 type Logger struct {
-	level                 level.Level
+	level                 atomicLevel
+	vmodule               atomic.Pointer[vModule]
 	label                 string
 	writerEncoders        []WriterEncoderPair
+	encoderWriters        []WriterEncoderPair // writerEncoders grouped by encoder, built by initLogger
 	hooks                 []Hook
 	stack                 bool
+	stackLevel            level.Level
 	metaKeys              *metaKeys
+	fieldNames            FieldNames
 	consolePrint          bool
 	consoleColor          bool
 	levelFieldMarshalFunc LevelFieldMarshalFunc
@@ -34,18 +42,47 @@ type Logger struct {
 	errorMarshalFunc      ErrorMarshalFunc
 	errorStackMarshalFunc ErrorStackMarshalFunc
 	timeFormat            string
+	durationUseInt        bool
+	callerSkipFrameCount  int
 
 	// each Logger instance has an independent *Record pool.
 	recordPool *recordPool
+
+	// reloaded holds the settings applied by ReloadConfig, nil if the config has never been reloaded.
+	reloaded atomic.Pointer[Logger]
+	// configOpts are the options given to NewFromConfig, which are applied again after each config reloaded.
+	configOpts []Option
+	reloadMu   sync.Mutex
+
+	// parent is the logger creating this sub logger, nil if it is not created by SubLogger.
+	parent *Logger
+	// subOpts are the options given to SubLogger, which are applied again after the parent reloaded config.
+	subOpts []Option
+	// base is the settings of the parent which these settings are derived from.
+	base *Logger
+}
+
+// atomicLevel is a level.Level which can be changed at runtime safely.
+type atomicLevel struct {
+	v atomic.Int32
+}
+
+func (a *atomicLevel) Load() level.Level {
+	return level.Level(a.v.Load())
+}
+
+func (a *atomicLevel) Store(lv level.Level) {
+	a.v.Store(int32(lv))
 }
 
 func createLogger() *Logger {
-	return &Logger{
-		level:                 level.Debug,
+	logger := &Logger{
 		label:                 "",
 		writerEncoders:        nil,
 		stack:                 false,
+		stackLevel:            level.Disabled,
 		metaKeys:              emptyMetaKes(),
+		fieldNames:            DefaultFieldNames(),
 		consolePrint:          false,
 		consoleColor:          false,
 		levelFieldMarshalFunc: GlobalLevelFieldMarshalFunc,
@@ -53,8 +90,12 @@ func createLogger() *Logger {
 		errorMarshalFunc:      GlobalErrorMarshalFunc,
 		errorStackMarshalFunc: GlobalErrorStackMarshalFunc,
 		timeFormat:            GlobalTimeFormat,
+		durationUseInt:        GlobalDurationValueUseInt,
+		callerSkipFrameCount:  0,
 		recordPool:            nil,
 	}
+	logger.level.Store(level.Debug)
+	return logger
 }
 
 // New creates a new *Logger with options optional.
@@ -71,13 +112,32 @@ func New(opts ...Option) *Logger {
 
 // SubLogger creates a new *Logger that inherit from parent logger.
 // Optional options for sub logger also be supported.
+// After the parent reloaded config by ReloadConfig, the sub logger inherits the new settings of the parent,
+// and the options are applied to them again.
 func (l *Logger) SubLogger(opts ...Option) *Logger {
-	logger := &Logger{
-		level:                 l.level,
+	logger := l.settings().inherit()
+	logger.parent = l
+	logger.subOpts = opts
+	logger.level.Store(l.level.Load())
+	logger.vmodule.Store(l.vmodule.Load())
+	// apply options
+	for _, opt := range opts {
+		opt(logger)
+	}
+	// init logger
+	logger.initLogger()
+	return logger
+}
+
+// inherit creates a new *Logger with the settings of l, except level and vmodule.
+func (l *Logger) inherit() *Logger {
+	return &Logger{
 		label:                 l.label,
 		writerEncoders:        l.writerEncoders,
 		stack:                 l.stack,
+		stackLevel:            l.stackLevel,
 		metaKeys:              l.metaKeys.Clone(),
+		fieldNames:            l.fieldNames,
 		consolePrint:          l.consolePrint,
 		consoleColor:          l.consoleColor,
 		levelFieldMarshalFunc: l.levelFieldMarshalFunc,
@@ -85,15 +145,11 @@ func (l *Logger) SubLogger(opts ...Option) *Logger {
 		errorMarshalFunc:      l.errorMarshalFunc,
 		errorStackMarshalFunc: l.errorStackMarshalFunc,
 		timeFormat:            l.timeFormat,
+		durationUseInt:        l.durationUseInt,
+		callerSkipFrameCount:  l.callerSkipFrameCount,
 		recordPool:            nil,
+		base:                  l,
 	}
-	// apply options
-	for _, opt := range opts {
-		opt(logger)
-	}
-	// init logger
-	logger.initLogger()
-	return logger
 }
 
 func (l *Logger) createRecord() Record {
@@ -102,13 +158,13 @@ func (l *Logger) createRecord() Record {
 		level:         level.Disabled,
 		label:         l.label,
 		stack:         l.stack,
-		useIntDur:     GlobalDurationValueUseInt,
+		useIntDur:     l.durationUseInt,
 		doneFunc:      nil,
 		logger:        l,
 	}
 	if l.consolePrint {
-		r.recordPackers = make([]recordPacker, len(l.writerEncoders)+1)
-		r.recordPackers[len(l.writerEncoders)] = &ConsolePacker{
+		r.recordPackers = make([]recordPacker, len(l.encoderWriters)+1)
+		r.recordPackers[len(l.encoderWriters)] = &ConsolePacker{
 			RecordPackerForWriter: RecordPackerForWriter{
 				record:               r,
 				meta:                 bytesPool.Get(),
@@ -122,15 +178,12 @@ func (l *Logger) createRecord() Record {
 			consoleColor: l.consoleColor,
 		}
 	} else {
-		r.recordPackers = make([]recordPacker, len(l.writerEncoders))
+		r.recordPackers = make([]recordPacker, len(l.encoderWriters))
 	}
-	for i, wep := range l.writerEncoders {
-		var enc Encoder
-		switch tmp := wep.enc.(type) {
-		case encoder.TextEncoder, *encoder.TextEncoder:
+	for i, wep := range l.encoderWriters {
+		enc := wep.enc
+		if isTextEncoder(enc) {
 			enc = NewTextEncoder(l.metaKeys.Keys()...)
-		default:
-			enc = tmp
 		}
 		r.recordPackers[i] = &RecordPackerForWriter{
 			record:               r,
@@ -144,8 +197,101 @@ func (l *Logger) createRecord() Record {
 }
 
 func (l *Logger) initLogger() {
-	// init Record pool
-	l.recordPool = newRecordPool(l.createRecord)
+	// group writers by encoder, so that each Record is encoded once for writers sharing an encoder
+	l.encoderWriters = groupWriterEncoders(l.writerEncoders)
+	// init Record pool, records of sub loggers are counted with the ones of the settings inherited,
+	// so that the writers shared are closed after all of them are done when the config reloaded.
+	var refs *recordRefs
+	if l.base != nil {
+		refs = l.base.recordPool.refs
+	}
+	l.recordPool = newRecordPool(l.createRecord, refs)
+}
+
+// groupWriterEncoders merges the pairs using a same encoder into one pair writing to all the writers of them,
+// in the order of the first pair of each encoder.
+// Text encoders are all the same since they are replaced by the one with the meta keys of the logger,
+// other encoders are the same if they are equal, e.g. JsonEnc, and never if they are not comparable.
+func groupWriterEncoders(weps []WriterEncoderPair) []WriterEncoderPair {
+	groups := make([]WriterEncoderPair, 0, len(weps))
+	writers := make([][]LevelWriter, 0, len(weps))
+	for _, wep := range weps {
+		i := 0
+		for ; i < len(groups); i++ {
+			if sameEncoder(groups[i].enc, wep.enc) {
+				break
+			}
+		}
+		if i == len(groups) {
+			groups = append(groups, WriterEncoderPair{enc: wep.enc})
+			writers = append(writers, nil)
+		}
+		writers[i] = append(writers[i], wep.writer)
+	}
+	for i := range groups {
+		if len(writers[i]) == 1 {
+			groups[i].writer = writers[i][0]
+		} else {
+			groups[i].writer = multiLevelWriter{writers[i]}
+		}
+	}
+	return groups
+}
+
+// sameEncoder reports whether records encoded by a can be written to writers of b.
+func sameEncoder(a, b Encoder) bool {
+	if isTextEncoder(a) && isTextEncoder(b) {
+		return true
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() || !va.Comparable() || !vb.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+func isTextEncoder(enc Encoder) bool {
+	switch enc.(type) {
+	case encoder.TextEncoder, *encoder.TextEncoder:
+		return true
+	}
+	return false
+}
+
+// settings returns the Logger holding the settings in effect,
+// which is the one applied by ReloadConfig latest, or the logger itself if never reloaded.
+// Settings of a sub logger are derived again if the parent reloaded config.
+// Level and vmodule are always held by the logger itself.
+func (l *Logger) settings() *Logger {
+	s := l.reloaded.Load()
+	if s == nil {
+		s = l
+	}
+	if l.parent != nil {
+		if ps := l.parent.settings(); s.base != ps {
+			return l.rederive(ps)
+		}
+	}
+	return s
+}
+
+// rederive derives the settings of the sub logger from the settings of the parent ps again.
+// The settings replaced are not retired here, since they share the record counter with the settings
+// they were derived from, which is retired by ReloadConfig of the parent.
+func (l *Logger) rederive(ps *Logger) *Logger {
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	if s := l.reloaded.Load(); s != nil && s.base == ps {
+		return s
+	}
+	// level and vmodule changed by options are ignored, since they are held by the sub logger itself
+	s := ps.inherit()
+	for _, opt := range l.subOpts {
+		opt(s)
+	}
+	s.initLogger()
+	l.reloaded.Store(s)
+	return s
 }
 
 // Flush forces any buffered data to be written out to all writers that implement the Flush() method.
@@ -155,16 +301,21 @@ func (l *Logger) initLogger() {
 func (l *Logger) Flush() (err error) {
 	type flushI interface{ Flush() error }
 	wg := sync.WaitGroup{}
+	errMu := sync.Mutex{}
 	flushRoutine := func(fw flushI) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			if e := fw.Flush(); e != nil {
-				err = e
+				errMu.Lock()
+				if err == nil {
+					err = e
+				}
+				errMu.Unlock()
 			}
 		}()
 	}
-	for _, writerEncoder := range l.writerEncoders {
+	for _, writerEncoder := range l.settings().writerEncoders {
 		switch w := writerEncoder.writer.(type) {
 		case levelWriterAdapter:
 			fw, ok := w.Writer.(flushI)
@@ -180,11 +331,11 @@ func (l *Logger) Flush() (err error) {
 			// Skip writers that don't need explicit flushing
 		case flushI:
 			// Flush writers that directly implement flushI
-			return w.Flush()
+			flushRoutine(w)
 		case multiLevelWriter:
 			// For multiLevelWriter, flush all underlying writers that support flushing
 			for _, writer := range w.writers {
-				if fw, ok := writer.(flushI); ok {
+				if fw, ok := unwrapLevelWriter(writer).(flushI); ok {
 					flushRoutine(fw)
 				}
 			}
@@ -196,52 +347,103 @@ func (l *Logger) Flush() (err error) {
 	return err
 }
 
-// Record create a new Record with basic.
-func (l *Logger) Record() Record {
-	if l.level == level.Disabled {
+// SetLevel changes the level of the logger at runtime.
+// Sub loggers created before are not affected.
+func (l *Logger) SetLevel(lv level.Level) {
+	l.level.Store(lv)
+}
+
+// GetLevel returns the level of the logger.
+func (l *Logger) GetLevel() level.Level {
+	return l.level.Load()
+}
+
+// SetVModule changes the level overrides by the file or package of call sites at runtime,
+// e.g. "scheduler/*=debug,db=warn". An empty spec removes all overrides.
+// See WithVModule for details of the spec. Sub loggers created before are not affected.
+func (l *Logger) SetVModule(spec string) error {
+	vm, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	l.vmodule.Store(vm)
+	return nil
+}
+
+// VModule returns the spec of the level overrides by the file or package of call sites.
+func (l *Logger) VModule() string {
+	if vm := l.vmodule.Load(); vm != nil {
+		return vm.spec
+	}
+	return ""
+}
+
+// newRecord gets a Record from the pool with the minimum level of the call site,
+// which is the caller of the exported method calling newRecord.
+func (l *Logger) newRecord() Record {
+	minLevel := l.level.Load()
+	if minLevel == level.Disabled {
 		return nilRecord
 	}
-	r := l.recordPool.Get()
-	r.WithLabels(l.label)
+	s := l.settings()
+	if vm := l.vmodule.Load(); vm != nil {
+		// skip newRecord and the exported method calling it
+		if lv, ok := vm.levelOf(2 + s.callerSkipFrameCount + CallerSkipFrameCount); ok {
+			minLevel = lv
+		}
+		// custom levels may be greater than Disabled, so call sites disabled are checked explicitly
+		if minLevel == level.Disabled {
+			return nilRecord
+		}
+	}
+	// the settings may be replaced by ReloadConfig concurrently, retry with the new ones if so
+	for !s.recordPool.acquire() {
+		s = l.settings()
+	}
+	r := s.recordPool.Get()
+	if lr, ok := r.(*LogRecord); ok {
+		lr.minLevel = minLevel
+	}
+	r.WithLabels(s.label)
 	return r
 }
 
+// Record create a new Record with basic.
+func (l *Logger) Record() Record {
+	return l.newRecord()
+}
+
 // Level create a new Record with the logger level given.
 func (l *Logger) Level(le level.Level) Record {
-	if l.level == level.Disabled {
-		return nilRecord
-	}
-	r := l.Record()
-	r.WithLevel(le)
-	return r
+	return l.newRecord().WithLevel(le)
 }
 
 // Debug create a new Record with debug level setting.
 func (l *Logger) Debug() Record {
-	return l.Level(level.Debug)
+	return l.newRecord().WithLevel(level.Debug)
 }
 
 // Info create a new Record with info level setting.
 func (l *Logger) Info() Record {
-	return l.Level(level.Info)
+	return l.newRecord().WithLevel(level.Info)
 }
 
 // Warn create a new Record with warn level setting.
 func (l *Logger) Warn() Record {
-	return l.Level(level.Warn)
+	return l.newRecord().WithLevel(level.Warn)
 }
 
 // Error create a new Record with error level setting.
 func (l *Logger) Error() Record {
-	return l.Level(level.Error)
+	return l.newRecord().WithLevel(level.Error)
 }
 
 // Fatal create a new Record with fatal level setting.
 func (l *Logger) Fatal() Record {
-	return l.Level(level.Fatal)
+	return l.newRecord().WithLevel(level.Fatal)
 }
 
 // Panic create a new Record with panic level setting.
 func (l *Logger) Panic() Record {
-	return l.Level(level.Panic)
+	return l.newRecord().WithLevel(level.Panic)
 }
@@ -0,0 +1,718 @@
+package rainbowlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rambollwong/rainbowcat/util"
+	"github.com/rambollwong/rainbowcat/writer/filewriter"
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+// NewFromConfig creates a new *Logger with default options and the config given,
+// options given are applied after the config, so that they can change the configured settings.
+//
+// Unlike WithConfig, it returns an error instead of triggering a panic.
+// The config is validated before creating any writer, a config.ValidationError
+// collecting all problems with field paths is returned if the config is invalid.
+// If a writer fails to be created, writers created before will be closed.
+func NewFromConfig(cfg config.LoggerConfig, opts ...Option) (*Logger, error) {
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	logger := createLogger()
+	WithDefault()(logger)
+	if err := applyConfig(logger, cfg); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(logger)
+	}
+	logger.configOpts = opts
+	logger.initLogger()
+	return logger, nil
+}
+
+// NewFromConfigFile loads the config from the file and creates a new *Logger by NewFromConfig.
+func NewFromConfigFile(configFile string, opts ...Option) (*Logger, error) {
+	cfg, err := config.LoadLoggerConfigFromFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("error load logger config from file %s: %w", configFile, err)
+	}
+	return NewFromConfig(*cfg, opts...)
+}
+
+// reloadDrainTimeout is the max duration to wait for records in use before closing writers replaced by ReloadConfig.
+const reloadDrainTimeout = time.Minute
+
+// ReloadConfig applies the config to the logger at runtime, the settings are built in the same way as NewFromConfig,
+// which replaces the writers created by config, while writers appended by options are kept.
+// The config is validated and all writers are created before any change is applied,
+// if an error occurs, the logger keeps working with the settings before.
+// Options given to NewFromConfig are applied again after the config, so that they still change the configured settings.
+//
+// Settings are switched atomically, records created before are still written by the writers replaced,
+// which are closed after those records are done. Sub loggers inherit the new settings,
+// and the records of them created before are waited for too.
+// A record which is never finished by Done keeps the writers replaced open until reloadDrainTimeout,
+// after that the writers are closed anyway and an error is reported to ErrorHandler.
+//
+// Sub loggers can not reload config, the config should be reloaded by the logger creating them.
+func (l *Logger) ReloadConfig(cfg config.LoggerConfig) error {
+	if l.parent != nil {
+		return errors.New("config can not be reloaded by a sub logger")
+	}
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+	l.reloadMu.Lock()
+	defer l.reloadMu.Unlock()
+	cur := l.settings()
+	next := createLogger()
+	WithDefault()(next)
+	next.writerEncoders = make([]WriterEncoderPair, 0, len(cur.writerEncoders))
+	next.hooks = cur.hooks[:len(cur.hooks):len(cur.hooks)] // options applied again never append to hooks of cur
+	next.level.Store(l.level.Load())
+	next.vmodule.Store(l.vmodule.Load())
+	var replaced []WriterEncoderPair
+	for _, wep := range cur.writerEncoders {
+		if wep.fromConfig {
+			replaced = append(replaced, wep)
+		} else {
+			next.writerEncoders = append(next.writerEncoders, wep)
+		}
+	}
+	if err := applyConfig(next, cfg); err != nil {
+		return err
+	}
+	// options given to NewFromConfig keep overriding the configured settings,
+	// writers and hooks appended by them are kept already.
+	writers, hooks := len(next.writerEncoders), len(cur.hooks)
+	for _, opt := range l.configOpts {
+		opt(next)
+	}
+	next.writerEncoders, next.hooks = next.writerEncoders[:writers], next.hooks[:hooks]
+	next.initLogger()
+
+	l.reloaded.Store(next)
+	l.vmodule.Store(next.vmodule.Load())
+	l.level.Store(next.level.Load())
+
+	go func() {
+		if !cur.recordPool.retire(reloadDrainTimeout) {
+			reportError(errors.New("records in use are still not done when closing writers replaced by reloading"))
+		}
+		for _, wep := range replaced {
+			if err := closeLevelWriter(wep.writer); err != nil {
+				reportError(fmt.Errorf("error close writer replaced by reloading: %w", err))
+			}
+		}
+	}()
+	return nil
+}
+
+// validateConfig validates the config by config.LoggerConfig.Validate,
+// and checks encoders, writer types and vmodule registered in this package as well.
+func validateConfig(cfg config.LoggerConfig) error {
+	if !cfg.Enable {
+		return nil
+	}
+	var errs config.ValidationError
+	if err := cfg.Validate(); err != nil {
+		if !errors.As(err, &errs) {
+			return err
+		}
+	}
+	if _, err := parseVModule(cfg.VModule); err != nil {
+		errs.Add("vmodule", err)
+	}
+	for i, name := range cfg.MetaKeys {
+		if _, ok := metaKeyOfName(DefaultFieldNames(), name); !ok {
+			errs.Addf(fmt.Sprintf("metaKeys[%d]", i), "unknown meta key %q", name)
+		}
+	}
+	colorKeys := make([]string, 0, len(cfg.ConsoleColors))
+	for key := range cfg.ConsoleColors {
+		colorKeys = append(colorKeys, key)
+	}
+	sort.Strings(colorKeys)
+	for _, key := range colorKeys {
+		if _, ok := colorKeyOfName(DefaultFieldNames(), key); !ok {
+			errs.Addf("consoleColors."+key, "unknown color key %q", key)
+		}
+		for i, color := range cfg.ConsoleColors[key] {
+			if _, err := ParseColor(color); err != nil {
+				errs.Add(fmt.Sprintf("consoleColors.%s[%d]", key, i), err)
+			}
+		}
+	}
+	if cfg.CallerFormat != "" {
+		if _, err := ParseCallerFormat(cfg.CallerFormat); err != nil {
+			errs.Add("callerFormat", err)
+		}
+	}
+	validateEncoder := func(field, encoder string) {
+		if _, err := parseEncoder(encoder); err != nil {
+			errs.Add(field, err)
+		}
+	}
+	if cfg.SizeRollingFileConfig.Enable {
+		validateEncoder("sizeRollingFileConfig.encoder", cfg.SizeRollingFileConfig.Encoder)
+	}
+	if cfg.TimeRollingFileConfig.Enable {
+		validateEncoder("timeRollingFileConfig.encoder", cfg.TimeRollingFileConfig.Encoder)
+	}
+	if cfg.HybridRollingFileConfig.Enable {
+		validateEncoder("hybridRollingFileConfig.encoder", cfg.HybridRollingFileConfig.Encoder)
+	}
+	if cfg.LevelSplitFileConfig.Enable {
+		validateEncoder("levelSplitFileConfig.encoder", cfg.LevelSplitFileConfig.Encoder)
+	}
+	if cfg.LabelRouteFileConfig.Enable {
+		validateEncoder("labelRouteFileConfig.encoder", cfg.LabelRouteFileConfig.Encoder)
+	}
+	for i, wc := range cfg.Writers {
+		if !wc.Enable {
+			continue
+		}
+		validateEncoder(fmt.Sprintf("writers[%d].encoder", i), wc.Encoder)
+		if wc.Type == "" {
+			continue
+		}
+		writerFactoriesMu.RLock()
+		_, ok := writerFactories[strings.ToLower(wc.Type)]
+		writerFactoriesMu.RUnlock()
+		if !ok {
+			errs.Addf(fmt.Sprintf("writers[%d].type", i), "unknown writer type %q", wc.Type)
+		}
+		// buffered records lose their levels, syslog would write them merged with the default severity
+		if wc.UseBufferedWriter && strings.EqualFold(wc.Type, WriterTypeSyslog) {
+			errs.Addf(fmt.Sprintf("writers[%d].useBufferedWriter", i), "buffered %s writer is not supported", wc.Type)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// parseEncoder parses the encoder by GlobalEncoderParseFunc, returns an error if it panics.
+func parseEncoder(encoder string) (enc Encoder, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return GlobalEncoderParseFunc(encoder), nil
+}
+
+// applyConfig sets the properties of the logger according to the config, and appends the writers configured.
+// If an error occurs, writers appended will be closed and removed.
+func applyConfig(logger *Logger, config config.LoggerConfig) (err error) {
+	if !config.Enable {
+		logger.level.Store(level.Disabled)
+		return nil
+	}
+	lv := level.FromString(config.Level)
+	if lv == level.None {
+		return errors.New("wrong logger level: " + config.Level)
+	}
+	stackLv := level.Disabled
+	if config.StackLevel != "" {
+		if stackLv = level.FromString(config.StackLevel); stackLv == level.None {
+			return errors.New("wrong stack level: " + config.StackLevel)
+		}
+	}
+	vm, err := parseVModule(config.VModule)
+	if err != nil {
+		return errors.New("wrong vmodule: " + err.Error())
+	}
+	var callerMarshalFunc CallerMarshalFunc
+	if config.CallerFormat != "" {
+		if callerMarshalFunc, err = ParseCallerFormat(config.CallerFormat); err != nil {
+			return errors.New("wrong caller format: " + config.CallerFormat)
+		}
+	}
+	colors := make(map[string][]int, len(config.ConsoleColors))
+	for key, names := range config.ConsoleColors {
+		if _, ok := colorKeyOfName(DefaultFieldNames(), key); !ok {
+			return errors.New("wrong console color key: " + key)
+		}
+		cs := make([]int, 0, len(names))
+		for _, name := range names {
+			c, err := ParseColor(name)
+			if err != nil {
+				return errors.New("wrong console color: " + name)
+			}
+			cs = append(cs, c)
+		}
+		colors[key] = cs
+	}
+	for _, name := range config.MetaKeys {
+		if _, ok := metaKeyOfName(DefaultFieldNames(), name); !ok {
+			return errors.New("wrong meta key: " + name)
+		}
+	}
+
+	writerCount := len(logger.writerEncoders)
+	defer func() {
+		if err != nil {
+			for _, wep := range logger.writerEncoders[writerCount:] {
+				closeWriter(wep.writer)
+			}
+			logger.writerEncoders = logger.writerEncoders[:writerCount]
+		}
+	}()
+	appendWriter := func(writer LevelWriter, encoder string) error {
+		enc, err := parseEncoder(encoder)
+		if err != nil {
+			closeWriter(writer)
+			return err
+		}
+		logger.writerEncoders = append(logger.writerEncoders, WriterEncoderPair{
+			writer:     writer,
+			enc:        enc,
+			fromConfig: true,
+		})
+		return nil
+	}
+
+	if config.SizeRollingFileConfig.Enable {
+		src := config.SizeRollingFileConfig
+		fileSizeLimit, err := util.ParseToBytesSize(src.FileSizeLimit, 1024)
+		if err != nil {
+			return errors.New("wrong file size limit: " + src.FileSizeLimit)
+		}
+		compression, err := ParseCompression(src.Compress)
+		if err != nil {
+			return errors.New("wrong compress: " + src.Compress)
+		}
+		writer, err := NewSizeRollingFileWriter(
+			src.LogFilePath,
+			src.LogFileBaseName,
+			src.MaxBackups,
+			fileSizeLimit,
+			compression,
+		)
+		if err != nil {
+			return errors.New("error new size rolling file writer: " + err.Error())
+		}
+		w, err := bufferedWriterByConfig(writer, src.UseBufferedWriter, src.WriterBufferSize, src.FlushInterval)
+		if err != nil {
+			return err
+		}
+		if err = appendWriter(w, src.Encoder); err != nil {
+			return err
+		}
+	}
+	if config.TimeRollingFileConfig.Enable {
+		trc := config.TimeRollingFileConfig
+		compression, err := ParseCompression(trc.Compress)
+		if err != nil {
+			return errors.New("wrong compress: " + trc.Compress)
+		}
+		writer, err := NewTimeRollingFileWriter(
+			trc.LogFilePath,
+			trc.LogFileBaseName,
+			trc.MaxBackups,
+			trc.RollingPeriod,
+			compression,
+		)
+		if err != nil {
+			return errors.New("error new time rolling file writer: " + err.Error())
+		}
+		w, err := bufferedWriterByConfig(writer, trc.UseBufferedWriter, trc.WriterBufferSize, trc.FlushInterval)
+		if err != nil {
+			return err
+		}
+		if err = appendWriter(w, trc.Encoder); err != nil {
+			return err
+		}
+	}
+	if config.HybridRollingFileConfig.Enable {
+		hrc := config.HybridRollingFileConfig
+		newRollingFile, err := hybridRollingFileWriterFactory(
+			hrc.LogFilePath,
+			hrc.RollingPeriod,
+			hrc.FileSizeLimit,
+			hrc.MaxBackups,
+			hrc.MaxAge,
+			hrc.MaxTotalSize,
+			hrc.Compress,
+			hrc.UseBufferedWriter,
+			hrc.WriterBufferSize,
+			hrc.FlushInterval,
+		)
+		if err != nil {
+			return err
+		}
+		w, err := newRollingFile(hrc.LogFileBaseName)
+		if err != nil {
+			return err
+		}
+		if err = appendWriter(w, hrc.Encoder); err != nil {
+			return err
+		}
+	}
+	if config.LevelSplitFileConfig.Enable {
+		lsc := config.LevelSplitFileConfig
+		newRollingFile, err := hybridRollingFileWriterFactory(
+			lsc.LogFilePath,
+			lsc.RollingPeriod,
+			lsc.FileSizeLimit,
+			lsc.MaxBackups,
+			lsc.MaxAge,
+			lsc.MaxTotalSize,
+			lsc.Compress,
+			lsc.UseBufferedWriter,
+			lsc.WriterBufferSize,
+			lsc.FlushInterval,
+		)
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(lsc.LogFileBaseName)
+		prefix := strings.TrimSuffix(lsc.LogFileBaseName, ext)
+		levels := make([]level.Level, 0, len(lsc.Levels))
+		for _, l := range lsc.Levels {
+			lv := level.FromString(l)
+			if lv == level.None {
+				return errors.New("wrong level split file level: " + l)
+			}
+			levels = append(levels, lv)
+		}
+		writers := make(map[level.Level]io.Writer, len(levels))
+		closeWriters := func() {
+			for _, w := range writers {
+				closeWriter(w)
+			}
+		}
+		for _, lv := range levels {
+			w, err := newRollingFile(prefix + "." + strings.ToLower(lv.String()) + ext)
+			if err != nil {
+				closeWriters()
+				return err
+			}
+			writers[lv] = w
+		}
+		defaultWriter, err := newRollingFile(lsc.LogFileBaseName)
+		if err != nil {
+			closeWriters()
+			return err
+		}
+		if err = appendWriter(NewLevelSplitWriter(writers, defaultWriter), lsc.Encoder); err != nil {
+			return err
+		}
+	}
+	if config.LabelRouteFileConfig.Enable {
+		lrc := config.LabelRouteFileConfig
+		newRollingFile, err := hybridRollingFileWriterFactory(
+			lrc.LogFilePath,
+			lrc.RollingPeriod,
+			lrc.FileSizeLimit,
+			lrc.MaxBackups,
+			lrc.MaxAge,
+			lrc.MaxTotalSize,
+			lrc.Compress,
+			lrc.UseBufferedWriter,
+			lrc.WriterBufferSize,
+			lrc.FlushInterval,
+		)
+		if err != nil {
+			return err
+		}
+		for _, route := range lrc.Routes {
+			if route.Label == "" || route.LogFileBaseName == "" {
+				return errors.New("wrong label route: label and logFileBaseName are required")
+			}
+		}
+		// routes with the same file share the writer
+		fileWriters := make(map[string]LevelWriter, len(lrc.Routes)+1)
+		for _, fileName := range append([]string{lrc.DefaultLogFileBaseName}, routeFiles(lrc.Routes)...) {
+			if _, ok := fileWriters[fileName]; ok || fileName == "" {
+				continue
+			}
+			w, err := newRollingFile(fileName)
+			if err != nil {
+				for _, fw := range fileWriters {
+					closeWriter(fw)
+				}
+				return err
+			}
+			fileWriters[fileName] = w
+		}
+		var router *LabelRouteWriter
+		if lrc.DefaultLogFileBaseName != "" {
+			router = NewLabelRouteWriter(fileWriters[lrc.DefaultLogFileBaseName])
+		} else {
+			router = NewLabelRouteWriter(nil)
+		}
+		for _, route := range lrc.Routes {
+			router.Route(route.Label, fileWriters[route.LogFileBaseName])
+		}
+		if err = appendWriter(router, lrc.Encoder); err != nil {
+			return err
+		}
+	}
+	for _, wc := range config.Writers {
+		if !wc.Enable {
+			continue
+		}
+		var minLevel level.Level
+		if wc.MinLevel != "" {
+			if minLevel = level.FromString(wc.MinLevel); minLevel == level.None {
+				return errors.New("wrong writer min level: " + wc.MinLevel)
+			}
+		}
+		writer, err := NewWriterByType(wc.Type, wc.Options)
+		if err != nil {
+			return errors.New("error new " + wc.Type + " writer: " + err.Error())
+		}
+		if wc.UseBufferedWriter {
+			bw, err := bufferedWriterByConfig(unwrapLevelWriter(writer), true, wc.WriterBufferSize, wc.FlushInterval)
+			if err != nil {
+				return err
+			}
+			writer = bw
+		}
+		if wc.MinLevel != "" {
+			writer = MinLevelWriter(writer, minLevel)
+		}
+		if err = appendWriter(writer, wc.Encoder); err != nil {
+			return err
+		}
+	}
+
+	logger.level.Store(lv)
+	logger.vmodule.Store(vm)
+	logger.label = config.Label
+	logger.stack = config.Stack
+	logger.stackLevel = stackLv
+	logger.consolePrint = config.EnableConsolePrinting
+	logger.consoleColor = config.EnableRainbowConsole
+	if config.TimeFormat != "" {
+		logger.timeFormat = config.TimeFormat
+	}
+	names := config.FieldNames
+	logger.setFieldNames(FieldNames{
+		Message:    names.Message,
+		Error:      names.Error,
+		ErrorStack: names.ErrorStack,
+		Stack:      names.Stack,
+		Time:       names.Time,
+		Level:      names.Level,
+		Label:      names.Label,
+		Caller:     names.Caller,
+		Func:       names.Func,
+	})
+	if len(config.MetaKeys) > 0 {
+		keys := make([]string, 0, len(config.MetaKeys))
+		for _, name := range config.MetaKeys {
+			key, _ := metaKeyOfName(logger.fieldNames, name)
+			keys = append(keys, key)
+		}
+		logger.metaKeys.SetKeys(keys)
+	}
+	// color keys are resolved after field names applied
+	for name, cs := range colors {
+		key, _ := colorKeyOfName(logger.fieldNames, name)
+		logger.metaKeys.SetKeyColors(key, cs)
+	}
+	if callerMarshalFunc != nil {
+		logger.callerMarshalFunc = callerMarshalFunc
+	}
+	logger.callerSkipFrameCount = config.CallerSkipFrameCount
+	switch strings.ToLower(config.DurationFormat) {
+	case "int":
+		logger.durationUseInt = true
+	case "float":
+		logger.durationUseInt = false
+	}
+	return nil
+}
+
+// metaKeyOfName returns the meta key of the name in config with the field names given, which is case-insensitive.
+func metaKeyOfName(names FieldNames, name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "time":
+		return names.Time, true
+	case "level":
+		return names.Level, true
+	case "label":
+		return names.Label, true
+	case "caller":
+		return names.Caller, true
+	case "func":
+		return names.Func, true
+	case "message":
+		return names.Message, true
+	}
+	return "", false
+}
+
+// colorKeyOfName returns the console color key of the name in config with the field names given, which is case-insensitive.
+// Besides names of meta keys, the name may be error, stack, keys, end, or "level" / "line" followed by a level name.
+func colorKeyOfName(names FieldNames, name string) (string, bool) {
+	if key, ok := metaKeyOfName(names, name); ok {
+		return key, true
+	}
+	lower := strings.ToLower(name)
+	switch lower {
+	case "error":
+		return names.Error, true
+	case "stack":
+		return names.Stack, true
+	case "keys":
+		return keysColorName, true
+	case "end":
+		return metaEndFieldName, true
+	}
+	for _, prefix := range []string{"level", "line"} {
+		rest, ok := strings.CutPrefix(lower, prefix)
+		if !ok {
+			continue
+		}
+		lv := level.FromString(rest)
+		if lv == level.None || lv == level.Disabled {
+			return "", false
+		}
+		levelKey, lineKey := levelColorKeys(lv)
+		if prefix == "level" {
+			return levelKey, true
+		}
+		return lineKey, true
+	}
+	return "", false
+}
+
+// routeFiles returns the file names of the label routes.
+func routeFiles(routes []config.LoggerLabelRouteConfig) []string {
+	files := make([]string, 0, len(routes))
+	for _, route := range routes {
+		files = append(files, route.LogFileBaseName)
+	}
+	return files
+}
+
+// closeWriter closes the writer unwrapped if it implements the io.Closer interface.
+func closeWriter(w io.Writer) {
+	if lw, ok := w.(LevelWriter); ok {
+		w = unwrapLevelWriter(lw)
+	}
+	if c, ok := w.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// closeLevelWriter closes the writer unwrapped if it implements the io.Closer interface, returns the error of closing.
+func closeLevelWriter(w LevelWriter) error {
+	if c, ok := unwrapLevelWriter(w).(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// reportError reports the error to ErrorHandler if set.
+func reportError(err error) {
+	if ErrorHandler != nil {
+		ErrorHandler(err)
+	}
+}
+
+// hybridRollingFileWriterFactory parses the rolling file arguments given, returns a function creating
+// a hybrid rolling file writer of the file name in logFilePath, which is buffered if useBufferedWriter is true.
+// If any argument is wrong, an error is returned.
+func hybridRollingFileWriterFactory(
+	logFilePath string,
+	rollingPeriod filewriter.RollingPeriod,
+	fileSizeLimit string,
+	maxBackups int,
+	maxAge, maxTotalSize, compress string,
+	useBufferedWriter bool,
+	writerBufferSize, flushInterval string,
+) (func(fileName string) (LevelWriter, error), error) {
+	sizeLimit, err := util.ParseToBytesSize(fileSizeLimit, 1024)
+	if err != nil {
+		return nil, errors.New("wrong file size limit: " + fileSizeLimit)
+	}
+	var age time.Duration
+	if maxAge != "" {
+		age, err = time.ParseDuration(maxAge)
+		if err != nil {
+			return nil, errors.New("wrong max age: " + maxAge)
+		}
+	}
+	var totalSize int64
+	if maxTotalSize != "" {
+		totalSize, err = util.ParseToBytesSize(maxTotalSize, 1024)
+		if err != nil {
+			return nil, errors.New("wrong max total size: " + maxTotalSize)
+		}
+	}
+	compression, err := ParseCompression(compress)
+	if err != nil {
+		return nil, errors.New("wrong compress: " + compress)
+	}
+	// check the buffer arguments before creating any file
+	if _, _, err = parseBufferArgs(useBufferedWriter, writerBufferSize, flushInterval); err != nil {
+		return nil, err
+	}
+	return func(fileName string) (LevelWriter, error) {
+		writer, err := NewHybridRollingFileWriter(
+			logFilePath,
+			fileName,
+			rollingPeriod,
+			sizeLimit,
+			maxBackups,
+			age,
+			totalSize,
+			compression,
+		)
+		if err != nil {
+			return nil, errors.New("error new hybrid rolling file writer: " + err.Error())
+		}
+		return bufferedWriterByConfig(writer, useBufferedWriter, writerBufferSize, flushInterval)
+	}, nil
+}
+
+// bufferedWriterByConfig wraps the writer with BufferedWriter if useBufferedWriter is true.
+// If the buffer size or the flush interval is wrong, the writer is closed and an error is returned.
+func bufferedWriterByConfig(writer io.Writer, useBufferedWriter bool, writerBufferSize, flushInterval string) (LevelWriter, error) {
+	bufferSize, interval, err := parseBufferArgs(useBufferedWriter, writerBufferSize, flushInterval)
+	if err != nil {
+		closeWriter(writer)
+		return nil, err
+	}
+	if !useBufferedWriter {
+		return LevelWriterAdapter(writer), nil
+	}
+	bw := NewBufferedWriterWithFlushInterval(writer, bufferSize, interval)
+	// the writer is created by config, close it together with the buffer
+	bw.closeW = true
+	return bw, nil
+}
+
+// parseBufferArgs parses the buffer size and the flush interval if useBufferedWriter is true.
+func parseBufferArgs(useBufferedWriter bool, writerBufferSize, flushInterval string) (int, time.Duration, error) {
+	if !useBufferedWriter {
+		return 0, 0, nil
+	}
+	bufferSize, err := util.ParseToBytesSize(writerBufferSize, 1024)
+	if err != nil {
+		return 0, 0, errors.New("wrong buffer size: " + writerBufferSize)
+	}
+	var interval time.Duration
+	if flushInterval != "" {
+		interval, err = time.ParseDuration(flushInterval)
+		if err != nil {
+			return 0, 0, errors.New("wrong flush interval: " + flushInterval)
+		}
+	}
+	return int(bufferSize), interval, nil
+}
@@ -0,0 +1,181 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	t.Run("Succeeded", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := config.DefaultLoggerConfig()
+		cfg.EnableConsolePrinting = false
+		cfg.Level = "INFO"
+		cfg.HybridRollingFileConfig.Enable = true
+		cfg.HybridRollingFileConfig.LogFilePath = dir
+		cfg.HybridRollingFileConfig.Encoder = "text"
+		buf := &bytes.Buffer{}
+		logger, err := NewFromConfig(cfg, WithMetaKeys(), AppendsEncoderWriters(TextEnc, buf))
+		require.NoError(t, err)
+		closeLoggerWriters(t, logger)
+
+		logger.Debug().Msg("ignored").Done()
+		logger.Info().Msg("hello").Done()
+		require.NoError(t, logger.Flush())
+		assert.Equal(t, level.Info, logger.GetLevel())
+		assert.Equal(t, "message=hello\n", buf.String())
+		bz, err := os.ReadFile(filepath.Join(dir, cfg.HybridRollingFileConfig.LogFileBaseName))
+		require.NoError(t, err)
+		assert.Equal(t, "message=hello\n", string(bz))
+	})
+
+	t.Run("CollectProblems", func(t *testing.T) {
+		cfg := config.DefaultLoggerConfig()
+		cfg.Level = "VERBOSE"
+		cfg.VModule = "db"
+		cfg.DurationFormat = "ms"
+		cfg.MetaKeys = []string{"time", "host"}
+		cfg.ConsoleColors = map[string][]string{"time": {"rainbow"}, "levelVerbose": {"red"}}
+		cfg.CallerFormat = "relative"
+		cfg.SizeRollingFileConfig.Enable = true
+		cfg.SizeRollingFileConfig.Encoder = "xml"
+		cfg.Writers = []config.LoggerWriterConfig{{Enable: true, Type: "kafka", Encoder: "json"}}
+
+		_, err := NewFromConfig(cfg)
+		var errs config.ValidationError
+		require.ErrorAs(t, err, &errs)
+		fields := make([]string, 0, len(errs))
+		for _, fe := range errs {
+			fields = append(fields, fe.Field)
+		}
+		assert.Equal(t, []string{
+			"level",
+			"durationFormat",
+			"vmodule",
+			"metaKeys[1]",
+			"consoleColors.levelVerbose",
+			"consoleColors.time[0]",
+			"callerFormat",
+			"sizeRollingFileConfig.encoder",
+			"writers[0].type",
+		}, fields)
+
+		assert.PanicsWithValue(t, "wrong logger level: VERBOSE", func() { New(WithConfig(cfg)) })
+	})
+
+	t.Run("FormatSettings", func(t *testing.T) {
+		cfg := config.DefaultLoggerConfig()
+		cfg.EnableConsolePrinting = false
+		cfg.MetaKeys = []string{"level", "caller", "func", "message"}
+		cfg.ConsoleColors = map[string][]string{"levelInfo": {"red", "Bold"}, "message": {"96"}}
+		cfg.FieldNames.Message = "msg"
+		cfg.CallerFormat = "short"
+		cfg.DurationFormat = "int"
+		buf := &bytes.Buffer{}
+		logger, err := NewFromConfig(cfg, AppendsEncoderWriters(JsonEnc, buf))
+		require.NoError(t, err)
+
+		logger.Info().Dur("cost", time.Second, 1500*time.Millisecond).Msg("hello").Done()
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "INFO", record[MetaLevelFieldName])
+		assert.Regexp(t, `^logger_config_test\.go:\d+$`, record[MetaCallerFieldName])
+		assert.Regexp(t, `\.TestNewFromConfig\.func\d+$`, record[MetaFuncFieldName])
+		assert.Equal(t, float64(1), record["cost"])
+		assert.Equal(t, "hello", record["msg"])
+		assert.NotContains(t, record, MetaTimeFieldName)
+
+		levelKey, _ := levelColorKeys(level.Info)
+		assert.Equal(t, []int{ColorRed, ColorBold}, logger.metaKeys.ConsoleColors(levelKey))
+		assert.Equal(t, []int{ColorHlCyan}, logger.metaKeys.ConsoleColors("msg"))
+		assert.Equal(t, "message", MsgFieldName)
+	})
+
+	t.Run("KeepSettingsNotConfigured", func(t *testing.T) {
+		msgFieldName, callerMarshalFunc := MsgFieldName, GlobalCallerMarshalFunc
+		defer func() { MsgFieldName, GlobalCallerMarshalFunc = msgFieldName, callerMarshalFunc }()
+		MsgFieldName, GlobalCallerMarshalFunc = "msg", PackageCallerMarshalFunc
+
+		cfgFile := filepath.Join(t.TempDir(), "rainbowlog.yaml")
+		require.NoError(t, os.WriteFile(cfgFile, []byte("rainbowlog:\n  level: INFO\n  enableConsolePrinting: false\n"), 0644))
+		buf := &bytes.Buffer{}
+		logger := New(
+			WithDefault(),
+			WithMetaKeys(MetaTimeFieldName, MetaCallerFieldName),
+			WithTimeFormat("2006"),
+			WithDurationUseInt(true),
+			WithConfigFile(cfgFile),
+			AppendsEncoderWriters(JsonEnc, buf),
+		)
+
+		logger.Info().Dur("cost", time.Second, 1500*time.Millisecond).Msg("hello").Done()
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, time.Now().Format("2006"), record[MetaTimeFieldName])
+		assert.Regexp(t, `^logger_config_test\.go:\d+$`, record[MetaCallerFieldName])
+		assert.Equal(t, float64(1), record["cost"])
+		assert.Equal(t, "hello", record["msg"])
+		assert.NotContains(t, record, MetaLevelFieldName)
+	})
+
+	t.Run("CloseWritersCreatedOnFailure", func(t *testing.T) {
+		dir := t.TempDir()
+		var closed bool
+		RegisterWriterFactory("closeRecorder", func(map[string]any) (LevelWriter, error) {
+			return &closeRecorder{closed: &closed}, nil
+		})
+		RegisterWriterFactory("failing", func(map[string]any) (LevelWriter, error) {
+			return nil, errors.New("connection refused")
+		})
+		cfg := config.DefaultLoggerConfig()
+		cfg.EnableConsolePrinting = false
+		cfg.HybridRollingFileConfig.Enable = true
+		cfg.HybridRollingFileConfig.LogFilePath = dir
+		cfg.Writers = []config.LoggerWriterConfig{
+			{Enable: true, Type: "closeRecorder", Encoder: "json"},
+			{Enable: true, Type: "failing", Encoder: "json"},
+		}
+
+		_, err := NewFromConfig(cfg)
+		require.EqualError(t, err, "error new failing writer: connection refused")
+		assert.True(t, closed)
+	})
+
+	t.Run("FromConfigFile", func(t *testing.T) {
+		_, err := NewFromConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.Error(t, err)
+
+		cfgFile := filepath.Join(t.TempDir(), "rainbowlog.yaml")
+		cfg := config.DefaultLoggerConfig()
+		cfg.Level = "WARN"
+		require.NoError(t, config.WriteConfigToFile(cfgFile, cfg))
+		logger, err := NewFromConfigFile(cfgFile, WithConsolePrint(false))
+		require.NoError(t, err)
+		assert.Equal(t, level.Warn, logger.GetLevel())
+	})
+}
+
+// closeRecorder is a writer recording whether it has been closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed *bool
+}
+
+func (c *closeRecorder) WriteLevel(_ level.Level, bz []byte) (int, error) {
+	return c.Write(bz)
+}
+
+func (c *closeRecorder) Close() error {
+	*c.closed = true
+	return nil
+}
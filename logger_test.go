@@ -0,0 +1,116 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/internal/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEncoder counts the fields encoded by the JsonEncoder wrapped,
+// the id makes encoders wrapping a same JsonEncoder distinct.
+type countingEncoder struct {
+	encoder.JsonEncoder
+	id    int
+	count *int
+}
+
+func (e countingEncoder) Key(dst []byte, key string) []byte {
+	*e.count++
+	return e.JsonEncoder.Key(dst, key)
+}
+
+func TestLogger_EncoderWriters(t *testing.T) {
+	t.Run("Grouping", func(t *testing.T) {
+		json1, json2, text, json3 := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+		logger := New(
+			WithMetaKeys(),
+			AppendsEncoderWriters(JsonEnc, json1),
+			AppendsEncoderWriters(JsonEnc, json2),
+			AppendsEncoderWriters(TextEnc, text),
+			AppendsEncoderWriters(encoder.TextEncoder{MetaKeys: []string{MetaLevelFieldName}}, text),
+			AppendsEncoderWriters(JsonEnc, json3),
+		)
+		require.Len(t, logger.encoderWriters, 2)
+		assert.Equal(t, JsonEnc, logger.encoderWriters[0].enc)
+		assert.True(t, isTextEncoder(logger.encoderWriters[1].enc))
+
+		logger.Info().Str("user", "alice").Msg("hello").Done()
+		for _, buf := range []*bytes.Buffer{json1, json2, json3} {
+			assert.Equal(t, `{"user":"alice","message":"hello"}`+"\n", buf.String())
+		}
+		assert.Equal(t, "user=alice message=hello\nuser=alice message=hello\n", text.String())
+	})
+
+	t.Run("EncodedOnce", func(t *testing.T) {
+		count := 0
+		shared := countingEncoder{id: 1, count: &count}
+		logger := New(
+			WithMetaKeys(),
+			AppendsEncoderWriters(shared, io.Discard),
+			AppendsEncoderWriters(shared, io.Discard),
+			AppendsEncoderWriters(shared, io.Discard),
+		)
+		logger.Info().Str("user", "alice").Msg("hello").Done()
+		assert.Equal(t, 2, count)
+
+		count = 0
+		logger = New(
+			WithMetaKeys(),
+			AppendsEncoderWriters(countingEncoder{id: 1, count: &count}, io.Discard),
+			AppendsEncoderWriters(countingEncoder{id: 2, count: &count}, io.Discard),
+		)
+		require.Len(t, logger.encoderWriters, 2)
+		logger.Info().Str("user", "alice").Msg("hello").Done()
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("WriterFailed", func(t *testing.T) {
+		errorHandler := ErrorHandler
+		defer func() { ErrorHandler = errorHandler }()
+		var errs []error
+		ErrorHandler = func(err error) { errs = append(errs, err) }
+
+		buf := &bytes.Buffer{}
+		logger := New(
+			WithMetaKeys(),
+			AppendsEncoderWriters(JsonEnc, &errorWriter{}),
+			AppendsEncoderWriters(JsonEnc, buf),
+		)
+		logger.Info().Msg("hello").Done()
+		assert.Equal(t, `{"message":"hello"}`+"\n", buf.String())
+		require.Len(t, errs, 1)
+		var mErr *MultiWriteError
+		require.True(t, errors.As(errs[0], &mErr))
+		require.Len(t, mErr.Failures, 1)
+		assert.Equal(t, 0, mErr.Failures[0].Index)
+	})
+}
+
+func BenchmarkLogger_MultiWriters(b *testing.B) {
+	for name, encs := range map[string][]Encoder{
+		"SharedEncoder": {JsonEnc, JsonEnc, JsonEnc},
+		"DistinctEncoders": {
+			countingEncoder{id: 1, count: new(int)},
+			countingEncoder{id: 2, count: new(int)},
+			countingEncoder{id: 3, count: new(int)},
+		},
+	} {
+		b.Run(name, func(b *testing.B) {
+			opts := []Option{WithMetaKeys(MetaTimeFieldName, MetaLevelFieldName)}
+			for _, enc := range encs {
+				opts = append(opts, AppendsEncoderWriters(enc, io.Discard))
+			}
+			logger := New(opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info().Str("user", "alice").Int("age", 18).Float64("score", 99.5).Msg("hello").Done()
+			}
+		})
+	}
+}
@@ -20,6 +20,25 @@ func (m *metaKeys) SetKeyColors(key string, colors []int) {
 	m.consoleColors[key] = colors
 }
 
+// Rename replaces the key old with the key new in keys and console colors.
+func (m *metaKeys) Rename(old, new string) {
+	if m == nil {
+		return
+	}
+	keys := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		if key == old {
+			key = new
+		}
+		keys[i] = key
+	}
+	m.keys = keys
+	if cs, ok := m.consoleColors[old]; ok {
+		delete(m.consoleColors, old)
+		m.consoleColors[new] = cs
+	}
+}
+
 func (m *metaKeys) Keys() []string {
 	if m == nil {
 		return nil
@@ -4,8 +4,6 @@ import (
 	"io"
 	"strings"
 
-	"github.com/rambollwong/rainbowcat/util"
-	"github.com/rambollwong/rainbowcat/writer/filewriter"
 	"github.com/rambollwong/rainbowlog/config"
 	"github.com/rambollwong/rainbowlog/level"
 )
@@ -18,10 +16,12 @@ type Option func(logger *Logger)
 // otherwise it may overwrite the modified configuration as the default.
 func WithDefault() Option {
 	return func(logger *Logger) {
-		logger.level = DefaultLevel
+		logger.level.Store(DefaultLevel)
 		logger.label = DefaultLabel
 		logger.stack = DefaultStack
-		logger.metaKeys = defaultMetaKeys()
+		logger.stackLevel = DefaultStackLevel
+		logger.fieldNames = DefaultFieldNames()
+		logger.metaKeys = defaultMetaKeysOf(logger.fieldNames)
 		logger.consolePrint = DefaultConsolePrint
 		logger.consoleColor = DefaultConsoleColor
 		logger.levelFieldMarshalFunc = GlobalLevelFieldMarshalFunc
@@ -29,13 +29,38 @@ func WithDefault() Option {
 		logger.errorMarshalFunc = GlobalErrorMarshalFunc
 		logger.errorStackMarshalFunc = GlobalErrorStackMarshalFunc
 		logger.timeFormat = GlobalTimeFormat
+		logger.durationUseInt = GlobalDurationValueUseInt
+		logger.callerSkipFrameCount = 0
 	}
 }
 
 // WithLevel sets logger level.
 func WithLevel(lv level.Level) Option {
 	return func(logger *Logger) {
-		logger.level = lv
+		logger.level.Store(lv)
+	}
+}
+
+// WithVModule sets level overrides by the file or package of call sites creating records,
+// e.g. "scheduler/*=debug,db=warn" prints debug records created in files of "scheduler" directories
+// and only warn or above records created in db.go or files of "db" directories.
+//
+// The spec is a comma-separated list of pattern=level. A pattern is matched against the path of
+// the call site file without ".go" suffix and the path of its directory, using as many trailing
+// path elements as the pattern has. Patterns support the syntax of path.Match and the first one matched wins.
+// Call sites not matched use the logger level, results of matching are cached per call site,
+// so that only a few stack frames are unwound to find the call site for each record.
+//
+// NOTICE: if the Logger methods are wrapped, CallerSkipFrameCount or WithCallerSkipFrameCount
+// should be set to find the call site.
+// It panics if the spec is invalid.
+func WithVModule(spec string) Option {
+	vm, err := parseVModule(spec)
+	if err != nil {
+		panic("wrong vmodule: " + err.Error())
+	}
+	return func(logger *Logger) {
+		logger.vmodule.Store(vm)
 	}
 }
 
@@ -54,6 +79,15 @@ func WithStack(enable bool) Option {
 	}
 }
 
+// WithStackLevel sets the minimum level of records having the stack of the goroutine added automatically,
+// same as Record.Stack called before Record.Done, e.g. WithStackLevel(level.Error).
+// level.Disabled disables it, which is the default.
+func WithStackLevel(lv level.Level) Option {
+	return func(logger *Logger) {
+		logger.stackLevel = lv
+	}
+}
+
 // WithMetaKeys sets meta key field names for each Record.
 // Meta keys data will be placed at the front of the Record.
 func WithMetaKeys(keys ...string) Option {
@@ -72,6 +106,44 @@ func WithMetaKeyColors(key string, colors ...int) Option {
 	}
 }
 
+// WithLevelColors sets ANSI codes of color for printing the level field of records of the level given,
+// which works for custom levels registered by level.Register too.
+//
+// NOTICE: LevelColors only useful for console printing if rainbow console enabled.
+// Colors defined in globals.go
+func WithLevelColors(lv level.Level, colors ...int) Option {
+	return func(logger *Logger) {
+		levelKey, _ := levelColorKeys(lv)
+		logger.metaKeys.SetKeyColors(levelKey, colors)
+	}
+}
+
+// WithLevelLineColors sets ANSI codes of color for printing the first meta field of records of the level given,
+// which marks the beginning of each line of the level, e.g. ColorBgGreen for INFO by default.
+//
+// NOTICE: LevelLineColors only useful for console printing if rainbow console enabled.
+// Colors defined in globals.go
+func WithLevelLineColors(lv level.Level, colors ...int) Option {
+	return func(logger *Logger) {
+		_, lineKey := levelColorKeys(lv)
+		logger.metaKeys.SetKeyColors(lineKey, colors)
+	}
+}
+
+// WithFieldNames sets the names of fields printed by the logger, empty names are left unchanged,
+// e.g. WithFieldNames(FieldNames{Message: "msg", Time: "ts"}).
+// Field names are inherited by sub loggers, and the global field names, e.g. MsgFieldName,
+// are only the defaults of loggers without this option.
+//
+// NOTICE: meta keys and colors of keys set before are renamed, so this option should be put after
+// WithDefault, WithMetaKeys and WithMetaKeyColors using the global field names,
+// while the new names should be used by these options put after this one.
+func WithFieldNames(names FieldNames) Option {
+	return func(logger *Logger) {
+		logger.setFieldNames(names)
+	}
+}
+
 // WithConsolePrint sets whether enable printing to console.
 func WithConsolePrint(enable bool) Option {
 	return func(logger *Logger) {
@@ -88,6 +160,7 @@ func WithRainbowConsole(enable bool) Option {
 }
 
 // AppendsEncoderWriters appends writers who use a same encoder to logger.
+// Records are encoded once for all writers using a same encoder, even if they are appended by different calls.
 func AppendsEncoderWriters(encoder Encoder, writers ...io.Writer) Option {
 	if encoder == nil || len(writers) == 0 {
 		return func(logger *Logger) {}
@@ -140,7 +213,8 @@ func WithErrorMarshalFunc(errorMarshalFunc ErrorMarshalFunc) Option {
 	}
 }
 
-// WithErrorStackMarshalFunc sets the ErrorStackMarshalFunc for logger.
+// WithErrorStackMarshalFunc sets the ErrorStackMarshalFunc for logger, which is invoked if stack enabled by WithStack,
+// e.g. ChainErrorStackMarshalFunc (by default) or FramesErrorStackMarshalFunc.
 func WithErrorStackMarshalFunc(errorStackMarshalFunc ErrorStackMarshalFunc) Option {
 	return func(logger *Logger) {
 		logger.errorStackMarshalFunc = errorStackMarshalFunc
@@ -154,94 +228,47 @@ func WithTimeFormat(timeFormat string) Option {
 	}
 }
 
+// WithDurationUseInt sets whether durations are printed with the integer part only by default,
+// which can be changed by Record.UseIntDur for each record.
+func WithDurationUseInt(enable bool) Option {
+	return func(logger *Logger) {
+		logger.durationUseInt = enable
+	}
+}
+
+// WithCallerSkipFrameCount sets the number of extra stack frames to skip to find the caller
+// for records of the logger, which is useful if the Logger methods are wrapped.
+// It works with the global CallerSkipFrameCount, and the vmodule matching call sites uses it too.
+func WithCallerSkipFrameCount(count int) Option {
+	return func(logger *Logger) {
+		logger.callerSkipFrameCount = count
+	}
+}
+
 // WithConfig sets the Logger's properties according to the provided configuration parameters.
 // If the Enable field in the configuration is false, the logger level will be set Disabled.
 // Normally, the WithDefault() option should be set before calling this option.
+// If the config is invalid or any writer fails to be created, trigger a panic,
+// use NewFromConfig to get an error instead.
 func WithConfig(config config.LoggerConfig) Option {
 	if !config.Enable {
 		return WithLevel(level.Disabled)
 	}
 	return func(logger *Logger) {
-		lv := level.FromString(config.Level)
-		if lv == level.None {
-			panic("wrong logger level: " + config.Level)
-		}
-		logger.level = lv
-		logger.label = config.Label
-		logger.stack = config.Stack
-		logger.consolePrint = config.EnableConsolePrinting
-		logger.consoleColor = config.EnableRainbowConsole
-		if config.TimeFormat != "" {
-			logger.timeFormat = config.TimeFormat
-		}
-		if config.SizeRollingFileConfig.Enable {
-			src := config.SizeRollingFileConfig
-			fileSizeLimit, err := util.ParseToBytesSize(src.FileSizeLimit, 1024)
-			if err != nil {
-				panic("wrong file size limit: " + src.FileSizeLimit)
-			}
-			writer, err := filewriter.NewSizeRollingFileWriter(
-				src.LogFilePath,
-				src.LogFileBaseName,
-				src.MaxBackups,
-				fileSizeLimit,
-			)
-			if err != nil {
-				panic("error new size rolling file writer: " + err.Error())
-			}
-			encoder := GlobalEncoderParseFunc(src.Encoder)
-			var w LevelWriter
-			if src.UseBufferedWriter {
-				bufferSize, err := util.ParseToBytesSize(src.WriterBufferSize, 1024)
-				if err != nil {
-					panic("wrong buffer size: " + src.WriterBufferSize)
-				}
-				w = BufferedLevelWriter(writer, int(bufferSize))
-			} else {
-				w = LevelWriterAdapter(writer)
-			}
-			logger.writerEncoders = append(logger.writerEncoders, WriterEncoderPair{
-				writer: w,
-				enc:    encoder,
-			})
-		}
-		if config.TimeRollingFileConfig.Enable {
-			trc := config.TimeRollingFileConfig
-			writer, err := filewriter.NewTimeRollingFileWriter(
-				trc.LogFilePath,
-				trc.LogFileBaseName,
-				trc.MaxBackups,
-				trc.RollingPeriod,
-			)
-			if err != nil {
-				panic("error new time rolling file writer: " + err.Error())
-			}
-			encoder := GlobalEncoderParseFunc(trc.Encoder)
-			var w LevelWriter
-			if trc.UseBufferedWriter {
-				bufferSize, err := util.ParseToBytesSize(trc.WriterBufferSize, 1024)
-				if err != nil {
-					panic("wrong buffer size: " + trc.WriterBufferSize)
-				}
-				w = BufferedLevelWriter(writer, int(bufferSize))
-			} else {
-				w = LevelWriterAdapter(writer)
-			}
-			logger.writerEncoders = append(logger.writerEncoders, WriterEncoderPair{
-				writer: w,
-				enc:    encoder,
-			})
+		if err := applyConfig(logger, config); err != nil {
+			panic(err.Error())
 		}
 	}
 }
 
 // WithConfigFile loads the log configuration from the specified configuration file,
 // sets the properties of the Logger according to the configuration parameters.
-// If an error occurs while loading the configuration file, trigger a panic.
+// If an error occurs while loading the configuration file, trigger a panic,
+// use NewFromConfigFile to get an error instead.
 func WithConfigFile(configFile string) Option {
 	cfg, err := config.LoadLoggerConfigFromFile(configFile)
 	if err != nil {
-		panic("error load logger config from file: " + configFile)
+		panic("error load logger config from file " + configFile + ": " + err.Error())
 	}
 	return WithConfig(*cfg)
 }
@@ -2,6 +2,8 @@ package rainbowlog
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rambollwong/rainbowcat/pool"
 )
@@ -12,16 +14,41 @@ type NewRecordFunc func() Record
 
 type recordPool struct {
 	pool *sync.Pool
+	refs *recordRefs
 }
 
-func newRecordPool(newFunc NewRecordFunc) *recordPool {
+// recordRefs counts records in use of the pools writing to the same writers,
+// which are the pools of a logger and its sub loggers.
+type recordRefs struct {
+	// n counts records got from the pools and not put back yet.
+	n       atomic.Int64
+	retired atomic.Bool
+}
+
+// newRecordPool creates a record pool counting records in use by refs, a new one is created if refs is nil.
+func newRecordPool(newFunc NewRecordFunc, refs *recordRefs) *recordPool {
+	if refs == nil {
+		refs = &recordRefs{}
+	}
 	return &recordPool{
 		pool: &sync.Pool{
 			New: func() interface{} {
 				return newFunc()
 			},
 		},
+		refs: refs,
+	}
+}
+
+// acquire reserves a record of the pool, returns false if the pool has been retired.
+// Get should be called only if acquire returns true.
+func (p *recordPool) acquire() bool {
+	p.refs.n.Add(1)
+	if p.refs.retired.Load() {
+		p.refs.n.Add(-1)
+		return false
 	}
+	return true
 }
 
 func (p *recordPool) Get() Record {
@@ -31,4 +58,27 @@ func (p *recordPool) Get() Record {
 func (p *recordPool) Put(r Record) {
 	r.Reset()
 	p.pool.Put(r)
+	p.refs.n.Add(-1)
+}
+
+// retire stops the pools sharing refs acquiring records, and waits for records acquired before to be put back.
+// It returns false if some records are still in use after the timeout.
+func (p *recordPool) retire(timeout time.Duration) bool {
+	p.refs.retired.Store(true)
+	if p.refs.n.Load() <= 0 {
+		return true
+	}
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-ticker.C:
+			if p.refs.n.Load() <= 0 {
+				return true
+			}
+		case <-deadline:
+			return false
+		}
+	}
 }
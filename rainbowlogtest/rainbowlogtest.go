@@ -0,0 +1,317 @@
+// Package rainbowlogtest provides a logger capturing records in memory and assertion helpers
+// for testing code that logs with rainbowlog.
+package rainbowlogtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog"
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+// Entry is a record captured by TestLogger.
+type Entry struct {
+	Level   level.Level    // Level of the record
+	Label   string         // Label of the record
+	Message string         // Message of the record
+	Error   string         // Error of the record set by Record.Err
+	Caller  string         // Caller of the record marshaled by CallerMarshalFunc, e.g. "/path/to/foo.go:42"
+	Fields  map[string]any // Other fields of the record decoded from JSON, e.g. numbers are float64
+	Raw     string         // JSON encoded record
+}
+
+// Field returns the value of the field decoded from JSON and whether the field exists.
+func (e Entry) Field(key string) (any, bool) {
+	v, ok := e.Fields[key]
+	return v, ok
+}
+
+// String returns the JSON encoded record.
+func (e Entry) String() string {
+	return e.Raw
+}
+
+// Entries is a list of captured records in order of writing.
+type Entries []Entry
+
+// Filter returns the entries matching the function given.
+func (es Entries) Filter(match func(e Entry) bool) Entries {
+	res := make(Entries, 0, len(es))
+	for _, e := range es {
+		if match(e) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// Level returns the entries of the level given.
+func (es Entries) Level(lv level.Level) Entries {
+	return es.Filter(func(e Entry) bool { return e.Level == lv })
+}
+
+// Label returns the entries of the label given.
+func (es Entries) Label(label string) Entries {
+	return es.Filter(func(e Entry) bool { return e.Label == label })
+}
+
+// Message returns the entries of the message given.
+func (es Entries) Message(msg string) Entries {
+	return es.Filter(func(e Entry) bool { return e.Message == msg })
+}
+
+// Messages returns the messages of entries.
+func (es Entries) Messages() []string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+// String returns the JSON encoded records, one line per record.
+func (es Entries) String() string {
+	sb := strings.Builder{}
+	for _, e := range es {
+		sb.WriteString(e.Raw)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// TestLogger is a *rainbowlog.Logger which captures all records in memory.
+// Captured records are printed by t.Log if the test fails.
+type TestLogger struct {
+	*rainbowlog.Logger
+	t       testing.TB
+	mu      sync.Mutex
+	entries Entries
+}
+
+// NewTestLogger creates a new TestLogger for the test.
+// The logger has level TRACE, prints nothing to console and has caller as the only meta key by default,
+// options given are applied after default options, so that they can change these settings.
+// Records are always captured in JSON by a writer appended after options given,
+// and the caller meta key is added to the meta keys set by options if missing, so that Entry.Caller is captured.
+// Other meta keys set by options, e.g. time, are captured in Entry.Fields.
+func NewTestLogger(t testing.TB, opts ...rainbowlog.Option) *TestLogger {
+	tl := &TestLogger{t: t}
+	options := make([]rainbowlog.Option, 0, len(opts)+5)
+	options = append(options,
+		rainbowlog.WithDefault(),
+		rainbowlog.WithLevel(level.Trace),
+		rainbowlog.WithConsolePrint(false),
+		// level and label are got from the writer directly
+		func(logger *rainbowlog.Logger) {
+			rainbowlog.WithMetaKeys(logger.FieldNames().Caller)(logger)
+		},
+	)
+	options = append(options, opts...)
+	options = append(options,
+		rainbowlog.AppendsEncoderWriters(rainbowlog.JsonEnc, &captureWriter{tl: tl}),
+		// the field name of caller may be changed by options given
+		func(logger *rainbowlog.Logger) {
+			keys := logger.MetaKeys()
+			if caller := logger.FieldNames().Caller; !slices.Contains(keys, caller) {
+				rainbowlog.WithMetaKeys(append(keys, caller)...)(logger)
+			}
+		},
+	)
+	tl.Logger = rainbowlog.New(options...)
+	t.Cleanup(func() {
+		if t.Failed() {
+			tl.mu.Lock()
+			defer tl.mu.Unlock()
+			for _, e := range tl.entries {
+				t.Log(e.Raw)
+			}
+		}
+	})
+	return tl
+}
+
+// Entries returns a copy of all records captured.
+func (tl *TestLogger) Entries() Entries {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	res := make(Entries, len(tl.entries))
+	copy(res, tl.entries)
+	return res
+}
+
+// Reset drops all records captured.
+func (tl *TestLogger) Reset() {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.entries = nil
+}
+
+// AssertLogged asserts that a record of the level and message has been logged with the fields given.
+// Fields are given in key-value pairs, e.g. AssertLogged(level.Info, "done", "count", 3).
+// Values are compared after being encoded to JSON and decoded back, so that 3 equals float64(3).
+func (tl *TestLogger) AssertLogged(lv level.Level, msg string, fields ...any) bool {
+	tl.t.Helper()
+	if len(fields)%2 != 0 {
+		tl.t.Errorf("fields should be key-value pairs, got %d values", len(fields))
+		return false
+	}
+	expected := make(map[string]any, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok {
+			tl.t.Errorf("field key should be a string, got %T", fields[i])
+			return false
+		}
+		v, err := normalize(fields[i+1])
+		if err != nil {
+			tl.t.Errorf("failed to encode value of field %s: %v", key, err)
+			return false
+		}
+		expected[key] = v
+	}
+	candidates := tl.Entries().Level(lv).Message(msg)
+	names := tl.FieldNames()
+	for _, e := range candidates {
+		if matchFields(e, names, expected) {
+			return true
+		}
+	}
+	if len(candidates) == 0 {
+		tl.t.Errorf("no %s record with message %q logged, records:\n%s", lv, msg, tl.Entries())
+	} else {
+		tl.t.Errorf("no %s record with message %q logged with fields %v, records found:\n%s",
+			lv, msg, expected, candidates)
+	}
+	return false
+}
+
+// AssertNotLogged asserts that no record of the level and message has been logged.
+func (tl *TestLogger) AssertNotLogged(lv level.Level, msg string) bool {
+	tl.t.Helper()
+	if found := tl.Entries().Level(lv).Message(msg); len(found) > 0 {
+		tl.t.Errorf("unexpected %s record with message %q logged:\n%s", lv, msg, found)
+		return false
+	}
+	return true
+}
+
+// AssertNoErrors asserts that no record of level ERROR, FATAL or PANIC has been logged.
+// Custom levels registered by level.Register are not counted, use AssertNoneAtOrAbove for them.
+func (tl *TestLogger) AssertNoErrors() bool {
+	tl.t.Helper()
+	found := tl.Entries().Filter(func(e Entry) bool {
+		return e.Level == level.Error || e.Level == level.Fatal || e.Level == level.Panic
+	})
+	if len(found) > 0 {
+		tl.t.Errorf("unexpected error records logged:\n%s", found)
+		return false
+	}
+	return true
+}
+
+// AssertNoneAtOrAbove asserts that no record of the level given or above has been logged,
+// including records of custom levels ranking above it. Records written without level are not counted.
+func (tl *TestLogger) AssertNoneAtOrAbove(lv level.Level) bool {
+	tl.t.Helper()
+	found := tl.Entries().Filter(func(e Entry) bool {
+		return e.Level >= lv && e.Level != level.None && e.Level != level.Disabled
+	})
+	if len(found) > 0 {
+		tl.t.Errorf("unexpected records of level %s or above logged:\n%s", lv, found)
+		return false
+	}
+	return true
+}
+
+// capture decodes the JSON encoded record and keeps it.
+func (tl *TestLogger) capture(label string, lv level.Level, bz []byte) error {
+	raw := strings.TrimRight(string(bz), "\n")
+	fields := make(map[string]any)
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return fmt.Errorf("rainbowlogtest: failed to decode record %s: %w", raw, err)
+	}
+	e := Entry{
+		Level: lv,
+		Label: label,
+		Raw:   raw,
+	}
+	names := tl.FieldNames()
+	e.Message, _ = fields[names.Message].(string)
+	e.Error, _ = fields[names.Error].(string)
+	e.Caller, _ = fields[names.Caller].(string)
+	delete(fields, names.Message)
+	delete(fields, names.Error)
+	delete(fields, names.Caller)
+	e.Fields = fields
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	tl.entries = append(tl.entries, e)
+	return nil
+}
+
+// captureWriter is the writer of TestLogger, which gets level and label of records directly.
+type captureWriter struct {
+	tl *TestLogger
+}
+
+var _ rainbowlog.LabelLevelWriter = (*captureWriter)(nil)
+
+func (w *captureWriter) Write(bz []byte) (int, error) {
+	return w.WriteLabelLevel("", level.None, bz)
+}
+
+func (w *captureWriter) WriteLevel(lv level.Level, bz []byte) (int, error) {
+	return w.WriteLabelLevel("", lv, bz)
+}
+
+func (w *captureWriter) WriteLabelLevel(label string, lv level.Level, bz []byte) (int, error) {
+	if err := w.tl.capture(label, lv, bz); err != nil {
+		return 0, err
+	}
+	return len(bz), nil
+}
+
+// matchFields checks whether the entry has all fields expected, error and caller are matched by the field names given.
+func matchFields(e Entry, names rainbowlog.FieldNames, expected map[string]any) bool {
+	for k, v := range expected {
+		var actual any
+		switch k {
+		case names.Error:
+			actual = e.Error
+		case names.Caller:
+			actual = e.Caller
+		default:
+			var ok bool
+			if actual, ok = e.Fields[k]; !ok {
+				return false
+			}
+		}
+		if !reflect.DeepEqual(actual, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalize encodes the value to JSON and decodes it back.
+func normalize(v any) (any, error) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res any
+	if err = json.Unmarshal(bz, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,141 @@
+package rainbowlogtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeT is a test helper recording errors instead of failing the test.
+type fakeT struct {
+	testing.TB
+	errs []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errs = append(f.errs, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeT) Cleanup(func()) {}
+
+func TestTestLogger(t *testing.T) {
+	t.Run("CaptureEntries", func(t *testing.T) {
+		tl := NewTestLogger(t, rainbowlog.WithLabels("app"))
+
+		tl.Info().Msg("started").Int("port", 8080).Done()
+		tl.Error().WithLabels("db").Err(errors.New("timeout")).Msg("query failed").Done()
+
+		entries := tl.Entries()
+		require.Len(t, entries, 2)
+		assert.Equal(t, level.Info, entries[0].Level)
+		assert.Equal(t, "app", entries[0].Label)
+		assert.Equal(t, "started", entries[0].Message)
+		assert.Equal(t, map[string]any{"port": float64(8080)}, entries[0].Fields)
+		assert.True(t, strings.Contains(entries[0].Caller, "rainbowlogtest_test.go:"), entries[0].Caller)
+		assert.Equal(t, "db", entries[1].Label)
+		assert.Equal(t, "timeout", entries[1].Error)
+
+		assert.Equal(t, []string{"query failed"}, entries.Filter(func(e Entry) bool {
+			return e.Label == "db"
+		}).Messages())
+		assert.Len(t, entries.Level(level.Info), 1)
+
+		tl.Reset()
+		assert.Empty(t, tl.Entries())
+	})
+
+	t.Run("AssertLogged", func(t *testing.T) {
+		tl := NewTestLogger(t)
+		tl.Info().Msg("done").Int("count", 3).Str("name", "job").Done()
+
+		assert.True(t, tl.AssertLogged(level.Info, "done"))
+		assert.True(t, tl.AssertLogged(level.Info, "done", "count", 3, "name", "job"))
+		assert.True(t, tl.AssertNotLogged(level.Warn, "done"))
+
+		ft := &fakeT{}
+		tl.t = ft
+		assert.False(t, tl.AssertLogged(level.Info, "done", "count", 4))
+		assert.False(t, tl.AssertLogged(level.Debug, "done"))
+		assert.False(t, tl.AssertNotLogged(level.Info, "done"))
+		assert.Len(t, ft.errs, 3)
+	})
+
+	t.Run("AssertNoErrors", func(t *testing.T) {
+		tl := NewTestLogger(t)
+		tl.Warn().Msg("slow").Done()
+		assert.True(t, tl.AssertNoErrors())
+
+		ft := &fakeT{}
+		tl.t = ft
+		tl.Error().Msg("failed").Done()
+		assert.False(t, tl.AssertNoErrors())
+		require.Len(t, ft.errs, 1)
+		assert.Contains(t, ft.errs[0], "failed")
+	})
+
+	t.Run("AssertNoErrorsWithCustomLevel", func(t *testing.T) {
+		tl := NewTestLogger(t)
+		// custom levels may rank above PANIC, e.g. an AUDIT level
+		audit := level.Disabled + 1
+		tl.Level(audit).Msg("audit").Done()
+		assert.True(t, tl.AssertNoErrors())
+
+		ft := &fakeT{}
+		tl.t = ft
+		assert.False(t, tl.AssertNoneAtOrAbove(audit))
+		require.Len(t, ft.errs, 1)
+		assert.Contains(t, ft.errs[0], "audit")
+	})
+
+	t.Run("AssertNoneAtOrAbove", func(t *testing.T) {
+		tl := NewTestLogger(t)
+		tl.Info().Msg("started").Done()
+		assert.True(t, tl.AssertNoneAtOrAbove(level.Warn))
+
+		ft := &fakeT{}
+		tl.t = ft
+		tl.Warn().Msg("slow").Done()
+		assert.False(t, tl.AssertNoneAtOrAbove(level.Warn))
+		require.Len(t, ft.errs, 1)
+		assert.Contains(t, ft.errs[0], "slow")
+	})
+
+	t.Run("MetaKeysOption", func(t *testing.T) {
+		tl := NewTestLogger(t, rainbowlog.WithMetaKeys(rainbowlog.MetaTimeFieldName))
+		tl.Info().Msg("started").Done()
+
+		entries := tl.Entries()
+		require.Len(t, entries, 1)
+		assert.True(t, strings.Contains(entries[0].Caller, "rainbowlogtest_test.go:"), entries[0].Caller)
+		_, ok := entries[0].Field(rainbowlog.MetaTimeFieldName)
+		assert.True(t, ok)
+	})
+
+	t.Run("FieldNamesOption", func(t *testing.T) {
+		tl := NewTestLogger(t, rainbowlog.WithFieldNames(rainbowlog.FieldNames{Message: "msg", Error: "err", Caller: "src"}))
+		tl.Error().Err(errors.New("timeout")).Msg("query failed").Done()
+
+		entries := tl.Entries()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "query failed", entries[0].Message)
+		assert.Equal(t, "timeout", entries[0].Error)
+		assert.True(t, strings.Contains(entries[0].Caller, "rainbowlogtest_test.go:"), entries[0].Caller)
+		assert.Empty(t, entries[0].Fields)
+		assert.True(t, tl.AssertLogged(level.Error, "query failed", "err", "timeout"))
+	})
+
+	t.Run("LevelOption", func(t *testing.T) {
+		tl := NewTestLogger(t, rainbowlog.WithLevel(level.Warn))
+		tl.Info().Msg("ignored").Done()
+		tl.Warn().Msg("kept").Done()
+		assert.Equal(t, []string{"kept"}, tl.Entries().Messages())
+	})
+}
@@ -28,6 +28,9 @@ type Record interface {
 	Msg(msg string) Record
 	Msgf(format string, v ...interface{}) Record
 	Err(err error) Record
+	AnErr(key string, err error) Record
+	Errs(key string, errs ...error) Record
+	Stack() Record
 	Str(key string, val string) Record
 	Strs(key string, vals ...string) Record
 	Stringer(key string, val fmt.Stringer) Record
@@ -66,6 +69,8 @@ type Record interface {
 	IPAddr(key string, ip net.IP) Record
 	IPPrefix(key string, pfx net.IPNet) Record
 	MACAddr(key string, ha net.HardwareAddr) Record
+	Func(f func(r Record)) Record
+	Lazy(key string, f func() any) Record
 }
 
 // LogRecord represents a log Record.
@@ -75,11 +80,14 @@ type LogRecord struct {
 	mu            sync.Mutex
 	recordPackers []recordPacker
 	level         level.Level
+	minLevel      level.Level // minimum level to print, the logger level or the vmodule level of the call site
 	label         string
 	msg           string
 	useIntDur     bool
 	stack         bool
+	stackAdded    bool // the stack field has been added by Stack or Done
 	doneFunc      func(msg string)
+	lazyFields    []lazyField // added by Func and Lazy, evaluated by Done
 
 	logger *Logger
 }
@@ -117,7 +125,8 @@ func (r *LogRecord) UseIntDur() Record {
 }
 
 func (r *LogRecord) strikeOrNot() bool {
-	if r.level >= r.logger.level {
+	// custom levels may be greater than Disabled, so disabled records are checked explicitly
+	if r.level != level.Disabled && r.level >= r.minLevel {
 		return false
 	}
 	return true
@@ -132,7 +141,12 @@ func (r *LogRecord) Reset() {
 	r.level = level.Disabled
 	r.label = ""
 	r.msg = ""
+	r.stackAdded = false
 	r.doneFunc = nil
+	// drop the references of closures
+	clear(r.lazyFields)
+	r.lazyFields = r.lazyFields[:0]
+	r.useIntDur = r.logger.durationUseInt
 }
 
 // Discard disables the Record that it won't be printed.
@@ -170,9 +184,17 @@ func (r *LogRecord) Done() {
 	for _, hook := range r.logger.hooks {
 		hook.RunHook(r, r.level, r.msg)
 	}
+	// hooks may discard the Record
+	if r.strikeOrNot() {
+		return
+	}
+	r.evalLazyFields()
 	if r.doneFunc != nil {
 		defer r.doneFunc(r.msg)
 	}
+	if !r.stackAdded && r.logger.stackLevel != level.Disabled && r.level >= r.logger.stackLevel {
+		r.addStack()
+	}
 
 	for _, rp := range r.recordPackers {
 		rp.Done()
@@ -200,7 +222,7 @@ func (r *LogRecord) Msgf(format string, v ...interface{}) Record {
 
 // Err sets the given err to the error field when err is not nil。
 // NOTICE: This method should only be called once。
-// Calling multiple times may cause unpredictable results。
+// Calling multiple times may cause unpredictable results, use AnErr or Errs for more errors.
 func (r *LogRecord) Err(err error) Record {
 	if r.strikeOrNot() || err == nil {
 		return r
@@ -211,6 +233,52 @@ func (r *LogRecord) Err(err error) Record {
 	return r
 }
 
+// AnErr adds the err as the field of the key when err is not nil,
+// with the stack of it as the field of key + "_" + the error stack field name if stack enabled.
+func (r *LogRecord) AnErr(key string, err error) Record {
+	if r.strikeOrNot() || err == nil {
+		return r
+	}
+	for _, rp := range r.recordPackers {
+		rp.AnErr(key, err)
+	}
+	return r
+}
+
+// Errs adds errs not nil as an array field of the key,
+// with stacks of them as an array field of key + "_" + the error stack field name if stack enabled and any found.
+func (r *LogRecord) Errs(key string, errs ...error) Record {
+	if r.strikeOrNot() || len(errs) == 0 {
+		return r
+	}
+	for _, rp := range r.recordPackers {
+		rp.Errs(key, errs)
+	}
+	return r
+}
+
+// Stack adds the stack of the current goroutine from the caller of this method to the stack field,
+// as an array of frames (func, file, line).
+// NOTICE: This method should only be called once, records of levels not lower than the one set by
+// WithStackLevel have the stack field added on Done if this method is not called.
+func (r *LogRecord) Stack() Record {
+	if r.strikeOrNot() || r.stackAdded {
+		return r
+	}
+	r.addStack()
+	return r
+}
+
+// addStack adds the stack from the caller of the exported method calling addStack to the stack field.
+func (r *LogRecord) addStack() {
+	r.stackAdded = true
+	// skip addStack and the exported method calling it
+	frames := captureStack(2 + r.logger.callerSkipFrameCount + CallerSkipFrameCount)
+	for _, rp := range r.recordPackers {
+		rp.Stack(r.logger.fieldNames.Stack, frames)
+	}
+}
+
 func (r *LogRecord) Str(key, val string) Record {
 	if r.strikeOrNot() {
 		return r
@@ -591,6 +659,50 @@ func (r *LogRecord) MACAddr(key string, ha net.HardwareAddr) Record {
 	return r
 }
 
+// Func calls f with the Record only if it will be printed, so that expensive fields can be added in f
+// without cost for records of disabled levels. f is called once whatever the number of writers,
+// by Done after hooks run, so it is not called if a hook discards the Record either,
+// and the fields added in f follow the fields added before Done, e.g. the message.
+func (r *LogRecord) Func(f func(r Record)) Record {
+	if r.strikeOrNot() || f == nil {
+		return r
+	}
+	r.lazyFields = append(r.lazyFields, lazyField{fn: f})
+	return r
+}
+
+// Lazy adds the value returned by f as the field of the key like Any,
+// f is called only if the Record will be printed, and once whatever the number of writers.
+// Like Func, f is called by Done after hooks run.
+func (r *LogRecord) Lazy(key string, f func() any) Record {
+	if r.strikeOrNot() || f == nil {
+		return r
+	}
+	r.lazyFields = append(r.lazyFields, lazyField{key: key, val: f})
+	return r
+}
+
+// lazyField is a field added by Func or Lazy.
+type lazyField struct {
+	key string
+	val func() any     // value of the field added by Lazy
+	fn  func(r Record) // function added by Func
+}
+
+// evalLazyFields calls the functions given to Func and Lazy in order.
+func (r *LogRecord) evalLazyFields() {
+	for _, lf := range r.lazyFields {
+		if lf.fn != nil {
+			lf.fn(r)
+			continue
+		}
+		val := lf.val()
+		for _, rp := range r.recordPackers {
+			rp.Any(lf.key, val)
+		}
+	}
+}
+
 type NilRecord struct {
 }
 
@@ -638,6 +750,18 @@ func (n *NilRecord) Err(err error) Record {
 	return n
 }
 
+func (n *NilRecord) AnErr(key string, err error) Record {
+	return n
+}
+
+func (n *NilRecord) Errs(key string, errs ...error) Record {
+	return n
+}
+
+func (n *NilRecord) Stack() Record {
+	return n
+}
+
 func (n *NilRecord) Str(key string, val string) Record {
 	return n
 }
@@ -789,3 +913,11 @@ func (n *NilRecord) IPPrefix(key string, pfx net.IPNet) Record {
 func (n *NilRecord) MACAddr(key string, ha net.HardwareAddr) Record {
 	return n
 }
+
+func (n *NilRecord) Func(f func(r Record)) Record {
+	return n
+}
+
+func (n *NilRecord) Lazy(key string, f func() any) Record {
+	return n
+}
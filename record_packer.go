@@ -3,7 +3,6 @@ package rainbowlog
 import (
 	"fmt"
 	"net"
-	"runtime"
 	"time"
 )
 
@@ -12,6 +11,9 @@ type recordPacker interface {
 	CallerSkip(skip int)
 	Msg(msg string)
 	Err(err error)
+	AnErr(key string, err error)
+	Errs(key string, errs []error)
+	Stack(key string, frames []StackFrame)
 	Done()
 
 	Str(key, val string)
@@ -84,28 +86,88 @@ func (j *RecordPackerForWriter) CallerSkip(skip int) {
 }
 
 func (j *RecordPackerForWriter) Msg(msg string) {
-	*j.raw = j.writerEncoderPair.enc.Key(*j.raw, MsgFieldName)
+	*j.raw = j.writerEncoderPair.enc.Key(*j.raw, j.record.logger.fieldNames.Message)
 	*j.raw = j.writerEncoderPair.enc.String(*j.raw, msg)
 }
 
 func (j *RecordPackerForWriter) Err(err error) {
+	j.AnErr(j.record.logger.fieldNames.Error, err)
+}
+
+func (j *RecordPackerForWriter) AnErr(key string, err error) {
 	if err == nil || err.Error() == "" {
 		return
 	}
-	j.Str(ErrFieldName, j.record.logger.errorMarshalFunc(err))
-	if j.record.stack && j.record.logger.errorStackMarshalFunc != nil {
-		switch esm := j.record.logger.errorStackMarshalFunc(err).(type) {
-		case nil:
-		case error:
-			j.Str(ErrStackFieldName, esm.Error())
-		case string:
-			j.Str(ErrStackFieldName, esm)
-		default:
-			j.Any(ErrStackFieldName, esm)
+	j.Str(key, j.record.logger.errorMarshalFunc(err))
+	j.errStack(j.errorStackKey(key), j.errorStack(err))
+}
+
+func (j *RecordPackerForWriter) Errs(key string, errs []error) {
+	msgs, stacks := j.errsOf(errs)
+	if len(msgs) == 0 {
+		return
+	}
+	j.Strs(key, msgs...)
+	if stacks != nil {
+		j.Any(j.errorStackKey(key), stacks)
+	}
+}
+
+// errorStackKey returns the key of the stack field of the error field,
+// which is the ErrorStack field name for the Error field, or key + "_" + the ErrorStack field name for others.
+func (j *RecordPackerForWriter) errorStackKey(key string) string {
+	if key == j.record.logger.fieldNames.Error {
+		return j.record.logger.fieldNames.ErrorStack
+	}
+	return key + "_" + j.record.logger.fieldNames.ErrorStack
+}
+
+// errsOf marshals errs except nil ones into messages and stacks,
+// stacks are nil if no stack found, or nil for errors without stack.
+func (j *RecordPackerForWriter) errsOf(errs []error) (msgs []string, stacks []any) {
+	found := false
+	for _, err := range errs {
+		if err == nil || err.Error() == "" {
+			continue
 		}
+		msgs = append(msgs, j.record.logger.errorMarshalFunc(err))
+		esm := j.errorStack(err)
+		if e, ok := esm.(error); ok {
+			esm = e.Error()
+		}
+		found = found || esm != nil
+		stacks = append(stacks, esm)
+	}
+	if !found {
+		stacks = nil
+	}
+	return msgs, stacks
+}
+
+// errorStack returns the stack of err marshaled by the ErrorStackMarshalFunc if stack enabled, or nil.
+func (j *RecordPackerForWriter) errorStack(err error) any {
+	if !j.record.stack || j.record.logger.errorStackMarshalFunc == nil {
+		return nil
+	}
+	return j.record.logger.errorStackMarshalFunc(err)
+}
+
+func (j *RecordPackerForWriter) errStack(key string, esm any) {
+	switch esm := esm.(type) {
+	case nil:
+	case error:
+		j.Str(key, esm.Error())
+	case string:
+		j.Str(key, esm)
+	default:
+		j.Any(key, esm)
 	}
 }
 
+func (j *RecordPackerForWriter) Stack(key string, frames []StackFrame) {
+	j.Any(key, frames)
+}
+
 func (j *RecordPackerForWriter) createMeta() {
 	j.meta = bytesPool.Get()
 	// begin
@@ -113,32 +175,41 @@ func (j *RecordPackerForWriter) createMeta() {
 	if j.record.logger.metaKeys == nil {
 		return
 	}
+	names := &j.record.logger.fieldNames
 	// range meta keys
 	for _, s := range j.record.logger.metaKeys.Keys() {
 		switch s {
-		case MetaTimeFieldName:
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaTimeFieldName)
+		case names.Time:
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Time)
 			*j.meta = j.writerEncoderPair.enc.Time(*j.meta, j.record.logger.timeFormat, TimestampFunc())
-		case MetaLabelFieldName:
+		case names.Label:
 			if j.record.label == "" {
 				continue
 			}
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaLabelFieldName)
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Label)
 			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.label)
-		case MetaLevelFieldName:
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaLevelFieldName)
+		case names.Level:
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Level)
 			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.logger.levelFieldMarshalFunc(j.record.level))
-		case MetaCallerFieldName:
+		case names.Caller:
 			if j.record.logger.callerMarshalFunc == nil {
 				continue
 			}
-			skip := j.callerSkipFrameCount + CallerSkipFrameCount + innerCallerSkipFrameCount
-			_, file, line, ok := runtime.Caller(skip)
+			skip := j.callerSkipFrameCount + j.record.logger.callerSkipFrameCount + CallerSkipFrameCount + innerCallerSkipFrameCount
+			frame, ok := callerFrameOf(skip)
+			if !ok {
+				continue
+			}
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Caller)
+			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.logger.callerMarshalFunc(frame.file, frame.line))
+		case names.Func:
+			skip := j.callerSkipFrameCount + j.record.logger.callerSkipFrameCount + CallerSkipFrameCount + innerCallerSkipFrameCount
+			frame, ok := callerFrameOf(skip)
 			if !ok {
 				continue
 			}
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaCallerFieldName)
-			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.logger.callerMarshalFunc(file, line))
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Func)
+			*j.meta = j.writerEncoderPair.enc.String(*j.meta, frame.function)
 		}
 	}
 }
@@ -155,7 +226,7 @@ func (j *RecordPackerForWriter) Done() {
 	// append end
 	*j.raw = j.writerEncoderPair.enc.LineBreak(*j.raw)
 	// write
-	_, err := j.writerEncoderPair.writer.WriteLevel(j.record.level, *j.raw)
+	_, err := writeLabelLevel(j.writerEncoderPair.writer, j.record.label, j.record.level, *j.raw)
 	if err != nil && ErrorHandler != nil {
 		ErrorHandler(err)
 	}
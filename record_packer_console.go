@@ -3,11 +3,9 @@ package rainbowlog
 import (
 	"fmt"
 	"net"
-	"runtime"
 	"strconv"
+	"strings"
 	"time"
-
-	"github.com/rambollwong/rainbowlog/level"
 )
 
 var _ recordPacker = (*ConsolePacker)(nil)
@@ -15,6 +13,14 @@ var _ recordPacker = (*ConsolePacker)(nil)
 type ConsolePacker struct {
 	RecordPackerForWriter
 	consoleColor bool
+	stack        []StackFrame
+	errChain     ErrorChain
+}
+
+func (j *ConsolePacker) Reset() {
+	j.RecordPackerForWriter.Reset()
+	j.stack = nil
+	j.errChain = nil
 }
 
 func (j *ConsolePacker) printCall(consoleColorsKey string, call func(j *ConsolePacker)) {
@@ -39,108 +45,149 @@ func (j *ConsolePacker) printCall(consoleColorsKey string, call func(j *ConsoleP
 }
 
 func (j *ConsolePacker) Msg(msg string) {
-	j.printCall(MsgFieldName, func(j *ConsolePacker) {
+	j.printCall(j.record.logger.fieldNames.Message, func(j *ConsolePacker) {
 		// key & value field print
 		j.RecordPackerForWriter.Msg(msg)
 	})
 }
 
 func (j *ConsolePacker) Err(err error) {
+	j.AnErr(j.record.logger.fieldNames.Error, err)
+}
+
+func (j *ConsolePacker) AnErr(key string, err error) {
 	if err == nil || err.Error() == "" {
 		return
 	}
-	j.printCall(ErrFieldName, func(j *ConsolePacker) {
-		j.RecordPackerForWriter.Err(err)
+	esm := j.errorStack(err)
+	tree := false
+	j.printCall(j.record.logger.fieldNames.Error, func(j *ConsolePacker) {
+		j.RecordPackerForWriter.Str(key, j.record.logger.errorMarshalFunc(err))
+		if tree = j.appendErrChain(esm); !tree {
+			j.errStack(j.errorStackKey(key), esm)
+		}
+	})
+	if tree {
+		j.printCall(keysColorName, func(j *ConsolePacker) {
+			*j.raw = j.writerEncoderPair.enc.Key(*j.raw, j.errorStackKey(key))
+		})
+	}
+}
+
+func (j *ConsolePacker) Errs(key string, errs []error) {
+	msgs, stacks := j.errsOf(errs)
+	if len(msgs) == 0 {
+		return
+	}
+	tree := false
+	var others []any
+	j.printCall(j.record.logger.fieldNames.Error, func(j *ConsolePacker) {
+		j.RecordPackerForWriter.Strs(key, msgs...)
+		for _, esm := range stacks {
+			if j.appendErrChain(esm) {
+				tree = true
+			} else if esm != nil {
+				others = append(others, esm)
+			}
+		}
+		if others != nil {
+			j.RecordPackerForWriter.Any(j.errorStackKey(key), others)
+		}
+	})
+	if tree && others == nil {
+		j.printCall(keysColorName, func(j *ConsolePacker) {
+			*j.raw = j.writerEncoderPair.enc.Key(*j.raw, j.errorStackKey(key))
+		})
+	}
+}
+
+// appendErrChain appends the error chain or stack to the ones printed as trees after the record by Done,
+// returns false if the esm is neither of them.
+func (j *ConsolePacker) appendErrChain(esm any) bool {
+	switch esm := esm.(type) {
+	case ErrorChain:
+		j.errChain = append(j.errChain, esm...)
+	case []StackFrame:
+		j.errChain = append(j.errChain, ErrorInfo{Stack: esm})
+	default:
+		return false
+	}
+	return true
+}
+
+// Stack prints the key only, frames are printed line by line after the record by Done.
+func (j *ConsolePacker) Stack(key string, frames []StackFrame) {
+	j.printCall(keysColorName, func(j *ConsolePacker) {
+		*j.raw = j.writerEncoderPair.enc.Key(*j.raw, key)
 	})
+	j.stack = frames
+}
+
+// printStack prints frames of the stack line by line with the colors of the stack key, e.g.
+//
+//	main.main()
+//	    /path/to/main.go:10
+func (j *ConsolePacker) printStack() {
+	j.printFrames(j.stack, "    ")
+}
+
+// printErrorChain prints errors of the chain as an indented tree with the colors of the error key,
+// followed by frames of their stacks with the colors of the stack key, e.g.
+//
+//	*fmt.wrapError: load config: open app.yaml: no such file or directory
+//	    main.loadConfig()
+//	        /path/to/main.go:20
+//	    *fs.PathError: open app.yaml: no such file or directory
+func (j *ConsolePacker) printErrorChain() {
+	for _, info := range j.errChain {
+		indent := strings.Repeat("    ", info.depth+1)
+		if info.Type != "" {
+			j.printCall(j.record.logger.fieldNames.Error, func(j *ConsolePacker) {
+				*j.raw = append(*j.raw, indent...)
+				*j.raw = append(*j.raw, info.Type...)
+				*j.raw = append(*j.raw, ": "...)
+				*j.raw = append(*j.raw, info.Msg...)
+			})
+			*j.raw = append(*j.raw, '\n')
+			indent += "    "
+		}
+		j.printFrames(info.Stack, indent)
+	}
+}
+
+// printFrames prints frames line by line with the indent given.
+func (j *ConsolePacker) printFrames(frames []StackFrame, indent string) {
+	for _, frame := range frames {
+		j.printCall(j.record.logger.fieldNames.Stack, func(j *ConsolePacker) {
+			*j.raw = append(*j.raw, indent...)
+			*j.raw = append(*j.raw, frame.Func...)
+			*j.raw = append(*j.raw, "()\n"...)
+			*j.raw = append(*j.raw, indent...)
+			*j.raw = append(*j.raw, "    "...)
+			*j.raw = append(*j.raw, frame.File...)
+			*j.raw = append(*j.raw, ':')
+			*j.raw = strconv.AppendInt(*j.raw, int64(frame.Line), 10)
+		})
+		*j.raw = append(*j.raw, '\n')
+	}
 }
 
 func (j *ConsolePacker) printMetaLevelStart(dst *[]byte) int {
 	if j.consoleColor {
-		switch j.record.level {
-		case level.Debug:
-			cs := j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelDebugFieldName)
-			if cs != nil {
-				for _, c := range cs {
-					*dst = colorStart(*dst, c)
-				}
-			}
-			return len(cs)
-		case level.Info:
-			cs := j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelInfoFieldName)
-			if cs != nil {
-				for _, c := range cs {
-					*dst = colorStart(*dst, c)
-				}
-			}
-			return len(cs)
-		case level.Warn:
-			cs := j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelWarnFieldName)
-			if cs != nil {
-				for _, c := range cs {
-					*dst = colorStart(*dst, c)
-				}
-			}
-			return len(cs)
-		case level.Error:
-			cs := j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelErrorFieldName)
-			if cs != nil {
-				for _, c := range cs {
-					*dst = colorStart(*dst, c)
-				}
-			}
-			return len(cs)
-		case level.Panic:
-			cs := j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelPanicFieldName)
-			if cs != nil {
-				for _, c := range cs {
-					*dst = colorStart(*dst, c)
-				}
-			}
-			return len(cs)
-		case level.Fatal:
-			cs := j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelFatalFieldName)
-			if cs != nil {
-				for _, c := range cs {
-					*dst = colorStart(*dst, c)
-				}
-			}
-			return len(cs)
-		case level.Trace:
-			cs := j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelTraceFieldName)
-			if cs != nil {
-				for _, c := range cs {
-					*dst = colorStart(*dst, c)
-				}
-			}
-			return len(cs)
-		default:
-			return 0
+		levelKey, _ := levelColorKeys(j.record.level)
+		cs := j.record.logger.metaKeys.ConsoleColors(levelKey)
+		for _, c := range cs {
+			*dst = colorStart(*dst, c)
 		}
+		return len(cs)
 	}
 	return 0
 }
 
 func (j *ConsolePacker) printFirstMetaStart(dst *[]byte) int {
 	if j.consoleColor {
-		var cs []int
-		switch j.record.level {
-		case level.Debug:
-			cs = j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelDebugLineFieldName)
-		case level.Info:
-			cs = j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelInfoLineFieldName)
-		case level.Warn:
-			cs = j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelWarnLineFieldName)
-		case level.Error:
-			cs = j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelErrorLineFieldName)
-		case level.Panic:
-			cs = j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelPanicLineFieldName)
-		case level.Fatal:
-			cs = j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelFatalLineFieldName)
-		case level.Trace:
-			cs = j.record.logger.metaKeys.ConsoleColors(metaKeysColorLevelTraceLineFieldName)
-		default:
-			return 0
-		}
+		_, lineKey := levelColorKeys(j.record.level)
+		cs := j.record.logger.metaKeys.ConsoleColors(lineKey)
 		if cs != nil {
 			for _, c := range cs {
 				*dst = colorStart(*dst, c)
@@ -191,25 +238,26 @@ func (j *ConsolePacker) createMeta() {
 	*j.meta = j.writerEncoderPair.enc.BeginMarker(*j.meta)
 	if j.record.logger.metaKeys == nil {
 		// if meta keys unset, use default
-		j.record.logger.metaKeys = defaultMetaKeys()
+		j.record.logger.metaKeys = defaultMetaKeysOf(j.record.logger.fieldNames)
 	}
+	names := &j.record.logger.fieldNames
 	// range meta keys
 	for i, s := range j.record.logger.metaKeys.Keys() {
 		switch s {
-		case MetaTimeFieldName:
+		case names.Time:
 			endI := j.printRainbowStart(j.meta, i, s)
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaTimeFieldName)
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Time)
 			*j.meta = j.writerEncoderPair.enc.Time(*j.meta, j.record.logger.timeFormat, time.Now())
 			j.printRainbowEnd(j.meta, i, endI)
-		case MetaLabelFieldName:
+		case names.Label:
 			if j.record.label == "" {
 				continue
 			}
 			endI := j.printRainbowStart(j.meta, i, s)
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaLabelFieldName)
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Label)
 			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.label)
 			j.printRainbowEnd(j.meta, i, endI)
-		case MetaLevelFieldName:
+		case names.Level:
 			var endI int
 			if i == 0 {
 				// First meta printing with rainbow
@@ -218,21 +266,31 @@ func (j *ConsolePacker) createMeta() {
 				// Others meta printing
 				endI = j.printMetaLevelStart(j.meta)
 			}
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaLevelFieldName)
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Level)
 			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.level.KeyFieldValue())
 			j.printRainbowEnd(j.meta, i, endI)
-		case MetaCallerFieldName:
+		case names.Caller:
 			if j.record.logger.callerMarshalFunc == nil {
 				continue
 			}
-			skip := j.callerSkipFrameCount + CallerSkipFrameCount + innerCallerSkipFrameCount
-			_, file, line, ok := runtime.Caller(skip)
+			skip := j.callerSkipFrameCount + j.record.logger.callerSkipFrameCount + CallerSkipFrameCount + innerCallerSkipFrameCount
+			frame, ok := callerFrameOf(skip)
+			if !ok {
+				continue
+			}
+			endI := j.printRainbowStart(j.meta, i, s)
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Caller)
+			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.logger.callerMarshalFunc(frame.file, frame.line))
+			j.printRainbowEnd(j.meta, i, endI)
+		case names.Func:
+			skip := j.callerSkipFrameCount + j.record.logger.callerSkipFrameCount + CallerSkipFrameCount + innerCallerSkipFrameCount
+			frame, ok := callerFrameOf(skip)
 			if !ok {
 				continue
 			}
 			endI := j.printRainbowStart(j.meta, i, s)
-			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, MetaCallerFieldName)
-			*j.meta = j.writerEncoderPair.enc.String(*j.meta, j.record.logger.callerMarshalFunc(file, line))
+			*j.meta = j.writerEncoderPair.enc.Key(*j.meta, names.Func)
+			*j.meta = j.writerEncoderPair.enc.String(*j.meta, frame.function)
 			j.printRainbowEnd(j.meta, i, endI)
 		}
 	}
@@ -260,6 +318,9 @@ func (j *ConsolePacker) Done() {
 	*j.raw = j.writerEncoderPair.enc.ObjectData(*j.meta, *j.raw)
 	// append end
 	*j.raw = j.writerEncoderPair.enc.LineBreak(*j.raw)
+	// append error chain and stack
+	j.printErrorChain()
+	j.printStack()
 	// write
 	_, err := j.writerEncoderPair.writer.WriteLevel(j.record.level, *j.raw)
 	if err != nil && ErrorHandler != nil {
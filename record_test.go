@@ -0,0 +1,162 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_AnErr(t *testing.T) {
+	t.Run("MarshalFuncs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(
+			WithMetaKeys(),
+			WithStack(true),
+			WithErrorMarshalFunc(func(err error) string { return "E: " + err.Error() }),
+			WithErrorStackMarshalFunc(func(err error) any { return "stack of " + err.Error() }),
+			AppendsEncoderWriters(JsonEnc, buf),
+		)
+
+		logger.Error().Err(errors.New("boom")).AnErr("cause", errors.New("timeout")).AnErr("ignored", nil).Done()
+		assert.Equal(t, `{"error":"E: boom","stack":"stack of boom","cause":"E: timeout","cause_stack":"stack of timeout"}`+"\n", buf.String())
+	})
+
+	t.Run("ConsoleTree", func(t *testing.T) {
+		logger := New(WithMetaKeys(), WithStack(true), WithConsolePrint(true), WithRainbowConsole(false))
+		r := logger.Error().(*LogRecord)
+		buf := &bytes.Buffer{}
+		r.recordPackers[0].(*ConsolePacker).writerEncoderPair.writer = LevelWriterAdapter(buf)
+
+		r.AnErr("cause", newCallersError("timeout")).Done()
+		lines := strings.Split(buf.String(), "\n")
+		require.Greater(t, len(lines), 3)
+		assert.Equal(t, "cause=timeout cause_stack=", lines[0])
+		assert.Equal(t, "    *rainbowlog.callersError: timeout", lines[1])
+	})
+}
+
+func TestRecord_Errs(t *testing.T) {
+	t.Run("WithoutStacks", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), WithStack(true), AppendsEncoderWriters(JsonEnc, buf))
+
+		logger.Error().Errs("errors", errors.New("a"), nil, fmt.Errorf("b: %w", errors.New("c"))).Errs("none", nil).Done()
+		assert.Equal(t, `{"errors":["a","b: c"]}`+"\n", buf.String())
+	})
+
+	t.Run("WithStacks", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), WithStack(true), AppendsEncoderWriters(JsonEnc, buf))
+
+		logger.Error().Errs("errors", errors.New("a"), newCallersError("b")).Done()
+		var record struct {
+			Errors []string          `json:"errors"`
+			Stacks []json.RawMessage `json:"errors_stack"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, []string{"a", "b"}, record.Errors)
+		require.Len(t, record.Stacks, 2)
+		assert.Equal(t, "null", string(record.Stacks[0]))
+		var chain []ErrorInfo
+		require.NoError(t, json.Unmarshal(record.Stacks[1], &chain))
+		require.Len(t, chain, 1)
+		assert.Equal(t, "*rainbowlog.callersError", chain[0].Type)
+		assert.NotEmpty(t, chain[0].Stack)
+	})
+
+	t.Run("ConsoleTree", func(t *testing.T) {
+		logger := New(WithMetaKeys(), WithStack(true), WithConsolePrint(true), WithRainbowConsole(false))
+		r := logger.Error().(*LogRecord)
+		buf := &bytes.Buffer{}
+		r.recordPackers[0].(*ConsolePacker).writerEncoderPair.writer = LevelWriterAdapter(buf)
+
+		r.Errs("errors", newCallersError("a"), errors.New("b"), newCallersError("c")).Done()
+		lines := strings.Split(buf.String(), "\n")
+		assert.Equal(t, "errors=[a,b,c] errors_stack=", lines[0])
+		assert.Contains(t, lines, "    *rainbowlog.callersError: a")
+		assert.Contains(t, lines, "    *rainbowlog.callersError: c")
+	})
+}
+
+func TestRecord_FuncAndLazy(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), WithLevel(level.Info), AppendsEncoderWriters(JsonEnc, buf))
+
+		called := 0
+		logger.Debug().
+			Func(func(r Record) { called++ }).
+			Lazy("lazy", func() any { called++; return "value" }).
+			Msg("hello").Done()
+		logger.Info().Discard().
+			Func(func(r Record) { called++ }).
+			Lazy("lazy", func() any { called++; return "value" }).
+			Done()
+		assert.Zero(t, called)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("MultipleWriters", func(t *testing.T) {
+		jsonBuf, textBuf := &bytes.Buffer{}, &bytes.Buffer{}
+		logger := New(
+			WithMetaKeys(),
+			AppendsEncoderWriters(JsonEnc, jsonBuf),
+			AppendsEncoderWriters(TextEnc, textBuf),
+		)
+
+		funcCalled, lazyCalled := 0, 0
+		logger.Info().
+			Func(func(r Record) { funcCalled++; r.Str("user", "alice") }).
+			Lazy("size", func() any { lazyCalled++; return 42 }).
+			Msg("hello").Done()
+		assert.Equal(t, 1, funcCalled)
+		assert.Equal(t, 1, lazyCalled)
+		assert.Equal(t, `{"message":"hello","user":"alice","size":42}`+"\n", jsonBuf.String())
+		assert.Equal(t, "message=hello user=alice size=42\n", textBuf.String())
+	})
+
+	t.Run("EvaluatedByDone", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), AppendsEncoderWriters(JsonEnc, buf))
+
+		size := 1
+		r := logger.Info().
+			Func(func(r Record) { r.Int("func", size) }).
+			Lazy("lazy", func() any { return size })
+		size = 2
+		r.Done()
+		assert.Equal(t, `{"func":2,"lazy":2}`+"\n", buf.String())
+	})
+
+	t.Run("DiscardedByHook", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(
+			WithMetaKeys(),
+			AppendsEncoderWriters(JsonEnc, buf),
+			AppendsHooks(HookFunc(func(r Record, lv level.Level, msg string) {
+				if msg == "sampled out" {
+					r.Discard()
+				}
+			})),
+		)
+
+		called := 0
+		logger.Info().
+			Func(func(r Record) { called++ }).
+			Lazy("lazy", func() any { called++; return "value" }).
+			Msg("sampled out").Done()
+		assert.Zero(t, called)
+		assert.Empty(t, buf.String())
+
+		logger.Info().Lazy("lazy", func() any { called++; return "value" }).Msg("kept").Done()
+		assert.Equal(t, 1, called)
+		assert.Equal(t, `{"message":"kept","lazy":"value"}`+"\n", buf.String())
+	})
+}
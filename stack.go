@@ -0,0 +1,40 @@
+package rainbowlog
+
+import (
+	"runtime"
+	"strings"
+)
+
+// stackMaxFrames is the maximum number of frames captured for the stack of a record.
+const stackMaxFrames = 64
+
+// StackFrame is a frame of the stack captured for a record, which is printed as {"func":"","file":"","line":0}.
+type StackFrame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// captureStack returns the frames of the current goroutine from the caller skip frames above
+// the caller of captureStack, frames of the runtime package are dropped.
+func captureStack(skip int) []StackFrame {
+	var pcs [stackMaxFrames]uintptr
+	// skip runtime.Callers and captureStack itself
+	n := runtime.Callers(skip+2, pcs[:])
+	return stackFrames(runtime.CallersFrames(pcs[:n]))
+}
+
+// stackFrames collects the frames, frames of the runtime package are dropped.
+func stackFrames(frames *runtime.Frames) []StackFrame {
+	var stack []StackFrame
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, StackFrame{Func: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
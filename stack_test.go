@@ -0,0 +1,69 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecord_Stack(t *testing.T) {
+	decodeStack := func(t *testing.T, buf *bytes.Buffer) []StackFrame {
+		var record struct {
+			Stack []StackFrame `json:"stacktrace"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		return record.Stack
+	}
+
+	t.Run("Stack", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), AppendsEncoderWriters(JsonEnc, buf))
+
+		logger.Info().Stack().Msg("hello").Done()
+		frames := decodeStack(t, buf)
+		require.NotEmpty(t, frames)
+		assert.Equal(t, "github.com/rambollwong/rainbowlog.TestRecord_Stack.func2", frames[0].Func)
+		assert.True(t, strings.HasSuffix(frames[0].File, "/stack_test.go"), frames[0].File)
+		assert.Positive(t, frames[0].Line)
+		for _, frame := range frames {
+			assert.False(t, strings.HasPrefix(frame.Func, "runtime."), frame.Func)
+		}
+	})
+
+	t.Run("StackLevel", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(WithMetaKeys(), WithStackLevel(level.Error), AppendsEncoderWriters(JsonEnc, buf))
+
+		logger.Warn().Msg("warn").Done()
+		assert.Equal(t, `{"message":"warn"}`+"\n", buf.String())
+
+		buf.Reset()
+		logger.Error().Msg("error").Done()
+		frames := decodeStack(t, buf)
+		require.NotEmpty(t, frames)
+		assert.Equal(t, "github.com/rambollwong/rainbowlog.TestRecord_Stack.func3", frames[0].Func)
+
+		buf.Reset()
+		logger.Error().Stack().Msg("error").Done()
+		assert.Equal(t, 1, strings.Count(buf.String(), `"stacktrace"`))
+	})
+
+	t.Run("ConsolePacker", func(t *testing.T) {
+		logger := New(WithMetaKeys(), WithConsolePrint(true), WithRainbowConsole(false))
+		r := logger.Info().(*LogRecord)
+		buf := &bytes.Buffer{}
+		r.recordPackers[0].(*ConsolePacker).writerEncoderPair.writer = LevelWriterAdapter(buf)
+
+		r.Msg("hello").Stack().Done()
+		lines := strings.Split(buf.String(), "\n")
+		require.Greater(t, len(lines), 3)
+		assert.Equal(t, "message=hello stacktrace=", lines[0])
+		assert.Equal(t, "    github.com/rambollwong/rainbowlog.TestRecord_Stack.func4()", lines[1])
+		assert.Regexp(t, `^        .+/stack_test\.go:\d+$`, lines[2])
+	})
+}
@@ -0,0 +1,128 @@
+package rainbowlog
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+// globalLoggerFuncPrefix is the prefix of functions of the global logger package,
+// which wrap the Logger methods, so that the caller of them is the call site.
+const globalLoggerFuncPrefix = "github.com/rambollwong/rainbowlog/log."
+
+// vModuleRule overrides the logger level for call sites matching the pattern.
+type vModuleRule struct {
+	pattern  string
+	segments int
+	lv       level.Level
+}
+
+// vModuleSite is the result of matching a call site, cached by the program counter of it.
+type vModuleSite struct {
+	wrapper bool // the call site is in the global logger package, the caller of it should be matched instead
+	matched bool
+	lv      level.Level
+}
+
+// vModule overrides the logger level by the file or package of the call site creating records,
+// results of matching are cached per call site.
+type vModule struct {
+	spec  string
+	rules []vModuleRule
+	cache sync.Map // uintptr -> vModuleSite
+}
+
+// parseVModule parses a vmodule spec, which is a comma-separated list of pattern=level,
+// e.g. "scheduler/*=debug,db=warn".
+//
+// A pattern is matched against the path of the call site file without ".go" suffix
+// and against the path of the directory of it, using as many trailing path elements as the pattern has,
+// so that "db" matches db.go and all files in a "db" directory, "scheduler/*" matches all files in a "scheduler" directory.
+// Patterns support the syntax of path.Match, and the first pattern matched wins.
+// Levels are parsed by level.ParseLevel, so custom levels registered are supported too.
+func parseVModule(spec string) (*vModule, error) {
+	vm := &vModule{spec: spec}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, lvStr, ok := strings.Cut(item, "=")
+		pattern, lvStr = strings.TrimSpace(pattern), strings.TrimSpace(lvStr)
+		if !ok || pattern == "" || lvStr == "" {
+			return nil, fmt.Errorf("invalid vmodule item %q, pattern=level expected", item)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid vmodule pattern %q: %w", pattern, err)
+		}
+		lv, err := level.ParseLevel(lvStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vmodule level of pattern %q: %w", pattern, err)
+		}
+		pattern = strings.TrimSuffix(strings.Trim(pattern, "/"), ".go")
+		vm.rules = append(vm.rules, vModuleRule{
+			pattern:  pattern,
+			segments: strings.Count(pattern, "/") + 1,
+			lv:       lv,
+		})
+	}
+	if len(vm.rules) == 0 {
+		return nil, nil
+	}
+	return vm, nil
+}
+
+// levelOf returns the level overriding the logger level for the call site skip frames above the caller.
+func (vm *vModule) levelOf(skip int) (level.Level, bool) {
+	var pcs [1]uintptr
+	// skip runtime.Callers and levelOf itself
+	for i := skip + 2; runtime.Callers(i, pcs[:]) > 0; i++ {
+		site := vm.site(pcs[0])
+		if !site.wrapper {
+			return site.lv, site.matched
+		}
+	}
+	return level.None, false
+}
+
+// site returns the cached result of matching the call site of the program counter.
+func (vm *vModule) site(pc uintptr) vModuleSite {
+	if v, ok := vm.cache.Load(pc); ok {
+		return v.(vModuleSite)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	var site vModuleSite
+	if strings.HasPrefix(frame.Function, globalLoggerFuncPrefix) {
+		site.wrapper = true
+	} else {
+		site.lv, site.matched = vm.match(frame.File)
+	}
+	vm.cache.Store(pc, site)
+	return site
+}
+
+// match finds the level of the first rule matching the file.
+func (vm *vModule) match(file string) (level.Level, bool) {
+	file = strings.TrimSuffix(file, ".go")
+	dir := path.Dir(file)
+	for _, rule := range vm.rules {
+		if matchTrailing(rule, file) || matchTrailing(rule, dir) {
+			return rule.lv, true
+		}
+	}
+	return level.None, false
+}
+
+// matchTrailing matches the rule against as many trailing path elements of p as the pattern has.
+func matchTrailing(rule vModuleRule, p string) bool {
+	i := len(p)
+	for n := 0; n < rule.segments && i >= 0; n++ {
+		i = strings.LastIndexByte(p[:i], '/')
+	}
+	ok, _ := path.Match(rule.pattern, p[i+1:])
+	return ok
+}
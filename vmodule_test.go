@@ -0,0 +1,121 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVModule(t *testing.T) {
+	t.Run("Match", func(t *testing.T) {
+		vm, err := parseVModule("internal/scheduler=trace, scheduler/*=debug,db=warn,*_handler=error")
+		require.NoError(t, err)
+		for file, expected := range map[string]level.Level{
+			"/src/app/internal/scheduler/queue.go": level.Trace,
+			"/src/app/scheduler/queue.go":          level.Debug,
+			"/src/app/store/db.go":                 level.Warn,
+			"/src/app/db/conn.go":                  level.Warn,
+			"/src/app/http/user_handler.go":        level.Error,
+		} {
+			lv, ok := vm.match(file)
+			assert.True(t, ok, file)
+			assert.Equal(t, expected, lv, file)
+		}
+		for _, file := range []string{"/src/app/dbx/conn.go", "/src/app/scheduler.go", "/src/app/main.go"} {
+			_, ok := vm.match(file)
+			assert.False(t, ok, file)
+		}
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		vm, err := parseVModule(" , ")
+		require.NoError(t, err)
+		assert.Nil(t, vm)
+		for _, spec := range []string{"db", "db=", "=debug", "db=verbose", "[=debug"} {
+			_, err = parseVModule(spec)
+			assert.Error(t, err, spec)
+		}
+		assert.Panics(t, func() { WithVModule("db=verbose") })
+	})
+
+	t.Run("OverrideLoggerLevel", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(
+			AppendsEncoderWriters(TextEnc, buf),
+			WithMetaKeys(),
+			WithLevel(level.Info),
+			WithVModule("vmodule_test=debug"),
+		)
+		logger.Debug().Msg("debug").Done()
+		logger.Level(level.Trace).Msg("trace").Done()
+		assert.Equal(t, "message=debug\n", buf.String())
+
+		buf.Reset()
+		require.NoError(t, logger.SetVModule("*_test=error"))
+		assert.Equal(t, "*_test=error", logger.VModule())
+		logger.Warn().Msg("warn").Done()
+		logger.Error().Msg("error").Done()
+		assert.Equal(t, "message=error\n", buf.String())
+
+		buf.Reset()
+		require.NoError(t, logger.SetVModule(""))
+		assert.Equal(t, "", logger.VModule())
+		logger.Debug().Msg("debug").Done()
+		logger.Info().Msg("info").Done()
+		assert.Equal(t, "message=info\n", buf.String())
+		assert.Error(t, logger.SetVModule("db"))
+	})
+
+	t.Run("DisabledCallSite", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(AppendsEncoderWriters(TextEnc, buf), WithMetaKeys(), WithLevel(level.Debug))
+		require.NoError(t, logger.SetVModule("vmodule_test=disabled"))
+		logger.Error().Msg("error").Done()
+		// custom levels may be greater than Disabled
+		logger.Level(level.Disabled + 1).Msg("custom").Done()
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("SetLevel", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := New(AppendsEncoderWriters(TextEnc, buf), WithMetaKeys(), WithLevel(level.Info))
+		logger.Debug().Msg("ignored").Done()
+		logger.SetLevel(level.Debug)
+		assert.Equal(t, level.Debug, logger.GetLevel())
+		logger.Debug().Msg("debug").Done()
+		logger.SetLevel(level.Disabled)
+		logger.Error().Msg("disabled").Done()
+		assert.Equal(t, "message=debug\n", buf.String())
+	})
+
+	t.Run("WithConfig", func(t *testing.T) {
+		cfg := config.DefaultLoggerConfig()
+		cfg.EnableConsolePrinting = false
+		cfg.Level = "ERROR"
+		cfg.VModule = "vmodule_test=info"
+		buf := &bytes.Buffer{}
+		logger := New(WithDefault(), WithConfig(cfg), AppendsEncoderWriters(TextEnc, buf), WithMetaKeys())
+		assert.Equal(t, "vmodule_test=info", logger.VModule())
+		logger.Info().Msg("info").Done()
+		assert.Equal(t, "message=info\n", buf.String())
+	})
+}
+
+func BenchmarkVModule(b *testing.B) {
+	for name, spec := range map[string]string{"Without": "", "Matched": "vmodule_test=info", "NotMatched": "db=info"} {
+		b.Run(name, func(b *testing.B) {
+			logger := New(AppendsEncoderWriters(TextEnc, io.Discard), WithMetaKeys(), WithLevel(level.Info))
+			require.NoError(b, logger.SetVModule(spec))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Info().Msg("info").Done()
+			}
+		})
+	}
+}
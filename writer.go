@@ -6,6 +6,8 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rambollwong/rainbowlog/level"
 )
@@ -78,35 +80,21 @@ type multiLevelWriter struct {
 }
 
 // Write implements the io.Writer interface by writing to all underlying writers.
-// It returns an error if any of the writers fails or performs a short write.
+// A failed writer does not stop writing to the others,
+// a *MultiWriteError describing the failed writers will be returned if any.
 func (t multiLevelWriter) Write(bz []byte) (n int, err error) {
-	for _, writer := range t.writers {
-		n, err = writer.Write(bz)
-		if err != nil {
-			return
-		}
-		if n != len(bz) {
-			err = io.ErrShortWrite
-			return
-		}
-	}
-	return
+	return writeAll(t.writers, false, bz, func(w LevelWriter) (int, error) {
+		return w.Write(bz)
+	})
 }
 
 // WriteLevel implements the LevelWriter interface by writing to all underlying writers.
-// It returns an error if any of the writers fails or performs a short write.
+// A failed writer does not stop writing to the others,
+// a *MultiWriteError describing the failed writers will be returned if any.
 func (t multiLevelWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
-	for _, writer := range t.writers {
-		n, err = writer.WriteLevel(lv, bz)
-		if err != nil {
-			return
-		}
-		if n != len(bz) {
-			err = io.ErrShortWrite
-			return
-		}
-	}
-	return
+	return writeAll(t.writers, false, bz, func(w LevelWriter) (int, error) {
+		return w.WriteLevel(lv, bz)
+	})
 }
 
 // MultiLevelWriter creates a writer that duplicates its writes to all the
@@ -124,30 +112,52 @@ func MultiLevelWriter(writers ...io.Writer) LevelWriter {
 // BufferedWriter is a buffered writer that uses a double buffering mechanism to improve write performance.
 // It automatically switches to another buffer when the current buffer is full and asynchronously flushes
 // the filled buffer data to the underlying writer.
+// If a flush interval is set, the buffered data will also be flushed periodically,
+// so that records will not stay in the buffer for long when logging is infrequent.
+//
+// Data failed to be flushed is kept in memory in order and written before any newer data by the next flush,
+// the error is reported to ErrorHandler and returned by the next Write or Flush.
 type BufferedWriter struct {
-	mu         sync.Mutex     // Buffer switch lock to ensure atomicity of buffer switching
-	wMu        sync.Mutex     // Underlying writer lock to ensure exclusive access to the underlying writer
-	wg         sync.WaitGroup // Used to wait for all asynchronous flush operations to complete
-	w          io.Writer      // Underlying writer, the actual data write target
-	bufSize    int            // Defined buffer size
-	bufA       *bytes.Buffer  // Buffer A, one of the double buffers
-	bufB       *bytes.Buffer  // Buffer B, one of the double buffers
-	bufCurrent *bytes.Buffer  // Pointer to the currently used buffer
-	closed     bool           // Flag indicating whether the writer is closed
+	mu            sync.Mutex     // Buffer switch lock to ensure atomicity of buffer switching
+	wMu           sync.Mutex     // Underlying writer lock to ensure exclusive access to the underlying writer
+	wg            sync.WaitGroup // Used to wait for all asynchronous flush operations to complete
+	w             io.Writer      // Underlying writer, the actual data write target
+	bufSize       int            // Defined buffer size
+	bufA          *bytes.Buffer  // Buffer A, one of the double buffers
+	bufB          *bytes.Buffer  // Buffer B, one of the double buffers
+	bufCurrent    *bytes.Buffer  // Pointer to the currently used buffer
+	pending       bytes.Buffer   // Data failed to be flushed, written before any newer data, guarded by wMu
+	closed        bool           // Flag indicating whether the writer is closed
+	flushInterval time.Duration  // Interval of flushing periodically, disabled if not positive
+	closeC        chan struct{}  // Used to stop the periodic flush goroutine
+	err           stickyError    // Error of the last failed asynchronous flush, returned by the next Write
+	closeW        bool           // Whether to close the underlying writer when closed, set for writers created by config
 }
 
 // NewBufferedWriter creates a new buffered writer.
 // The parameter w is the underlying io.Writer, and bufSize is the size of each buffer.
 // The return value is a BufferedWriter instance that implements the io.Writer interface.
 func NewBufferedWriter(w io.Writer, bufSize int) *BufferedWriter {
+	return NewBufferedWriterWithFlushInterval(w, bufSize, 0)
+}
+
+// NewBufferedWriterWithFlushInterval creates a new buffered writer which flushes the buffered data
+// every flushInterval in background. If flushInterval is not positive, the periodic flush is disabled.
+// The goroutine of periodic flush will be stopped when the writer is closed.
+func NewBufferedWriterWithFlushInterval(w io.Writer, bufSize int, flushInterval time.Duration) *BufferedWriter {
 	bw := &BufferedWriter{
-		w:       w,
-		bufSize: bufSize,
-		bufA:    bytes.NewBuffer(make([]byte, 0, bufSize)), // Initialize buffer A
-		bufB:    bytes.NewBuffer(make([]byte, 0, bufSize)), // Initialize buffer B
-		closed:  false,
+		w:             w,
+		bufSize:       bufSize,
+		bufA:          bytes.NewBuffer(make([]byte, 0, bufSize)), // Initialize buffer A
+		bufB:          bytes.NewBuffer(make([]byte, 0, bufSize)), // Initialize buffer B
+		closed:        false,
+		flushInterval: flushInterval,
+		closeC:        make(chan struct{}),
 	}
 	bw.bufCurrent = bw.bufA // Initially use buffer A
+	if flushInterval > 0 {
+		go bw.flushPeriodically()
+	}
 	return bw
 }
 
@@ -155,11 +165,18 @@ func NewBufferedWriter(w io.Writer, bufSize int) *BufferedWriter {
 // It uses the BufferedWriter to buffer writes and flushes them asynchronously for improved performance.
 // The bufSize parameter specifies the size of each internal buffer.
 func BufferedLevelWriter(w io.Writer, bufSize int) LevelWriter {
-	return LevelWriterAdapter(NewBufferedWriter(w, bufSize))
+	return NewBufferedWriter(w, bufSize)
+}
+
+// BufferedLevelWriterWithFlushInterval is the same as BufferedLevelWriter,
+// and the buffered data will also be flushed every flushInterval.
+func BufferedLevelWriterWithFlushInterval(w io.Writer, bufSize int, flushInterval time.Duration) LevelWriter {
+	return NewBufferedWriterWithFlushInterval(w, bufSize, flushInterval)
 }
 
 // Write implements the io.Writer interface, writing data to the buffer.
 // When the buffer is full, it automatically switches to another buffer and asynchronously flushes the filled buffer.
+// If a previous asynchronous flush failed, the data is still buffered and the error of that flush is returned.
 func (bw *BufferedWriter) Write(bz []byte) (n int, err error) {
 	bw.mu.Lock()
 	defer bw.mu.Unlock()
@@ -167,6 +184,7 @@ func (bw *BufferedWriter) Write(bz []byte) (n int, err error) {
 	if bw.closed {
 		return 0, os.ErrClosed
 	}
+	flushErr := bw.err.take()
 
 	writeSize := len(bz)
 	written := 0
@@ -193,10 +211,16 @@ func (bw *BufferedWriter) Write(bz []byte) (n int, err error) {
 		}
 	}
 
-	return written, nil
+	return written, flushErr
+}
+
+// WriteLevel implements the LevelWriter interface by ignoring the level.
+func (bw *BufferedWriter) WriteLevel(_ level.Level, bz []byte) (n int, err error) {
+	return bw.Write(bz)
 }
 
-// Flush forcibly flushes the data in the current buffer to the underlying writer.
+// Flush forcibly flushes the data failed to be flushed before and the data in the current buffer
+// to the underlying writer. If any data is still failed to be written, the error will be returned.
 func (bw *BufferedWriter) Flush() error {
 	bw.mu.Lock()
 	defer bw.mu.Unlock()
@@ -205,6 +229,7 @@ func (bw *BufferedWriter) Flush() error {
 
 // Close closes the writer, ensuring all buffer data is flushed.
 // This method waits for all asynchronous flush operations to complete before returning.
+// If any data is failed to be flushed, the error will be returned and the writer is kept open.
 func (bw *BufferedWriter) Close() error {
 	bw.mu.Lock()
 	defer bw.mu.Unlock()
@@ -222,9 +247,32 @@ func (bw *BufferedWriter) Close() error {
 	bw.wg.Wait()
 
 	bw.closed = true
+	close(bw.closeC)
+	if c, ok := bw.w.(io.Closer); ok && bw.closeW {
+		return c.Close()
+	}
 	return nil
 }
 
+// Reopen reopens the underlying writer if it implements the Reopener interface.
+// Writing is blocked until reopening completes, so that the data remaining in buffers
+// will be written into the reopened file instead of the rotated one.
+func (bw *BufferedWriter) Reopen() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	r, ok := bw.w.(Reopener)
+	if !ok {
+		return nil
+	}
+	// Wait for the asynchronous flush operation in progress to complete
+	bw.wg.Wait()
+
+	bw.wMu.Lock()
+	defer bw.wMu.Unlock()
+	return r.Reopen()
+}
+
 // swapAndFlush switches the currently used buffer and starts a goroutine to asynchronously flush the old buffer's data.
 func (bw *BufferedWriter) swapAndFlush() error {
 	// Wait for previous asynchronous flush operations to complete to ensure no concurrent access to the same buffer
@@ -242,48 +290,107 @@ func (bw *BufferedWriter) swapAndFlush() error {
 	bw.wg.Add(1)
 	go func(toWrite *bytes.Buffer) {
 		defer bw.wg.Done()
-		e := bw.flushBuffer(toWrite)
-		if e != nil {
-			// Use panic instead of returning an error because this is running in a goroutine.
-			// Consider using a more graceful error handling mechanism, such as logging the error
-			// and possibly notifying the user or taking corrective action.
-			// For now, we will log the error and continue.
-			fmt.Fprintf(os.Stderr, "failed to flush buffer: %v\n", e)
+		if e := bw.flushBuffer(toWrite); e != nil {
+			bw.err.set(e)
 		}
 	}(toWrite)
 
 	return nil
 }
 
-// flushCurrent flushes the data in the current buffer.
-// If the current buffer is empty, no operation is performed.
+// flushPeriodically flushes the data in the current buffer asynchronously every flush interval
+// until the writer is closed.
+func (bw *BufferedWriter) flushPeriodically() {
+	ticker := time.NewTicker(bw.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bw.closeC:
+			return
+		case <-ticker.C:
+			bw.mu.Lock()
+			if !bw.closed && bw.bufCurrent.Len() > 0 {
+				_ = bw.swapAndFlush()
+			}
+			bw.mu.Unlock()
+		}
+	}
+}
+
+// flushCurrent flushes the data failed to be flushed before and the data in the current buffer.
+// If there is no data, no operation is performed.
 func (bw *BufferedWriter) flushCurrent() error {
 	bw.wg.Wait()
-
-	if bw.bufCurrent.Len() == 0 {
-		return nil
-	}
 	return bw.flushBuffer(bw.bufCurrent)
 }
 
-// flushBuffer writes the data in the specified buffer to the underlying writer and resets the buffer.
+// flushBuffer writes the data failed to be flushed before and the data in the specified buffer
+// to the underlying writer, then resets the buffer.
+// The data not written is kept in pending, so that it will be written before any newer data by the next flush.
 func (bw *BufferedWriter) flushBuffer(buf *bytes.Buffer) error {
-	// If the buffer is empty, no flush is needed
-	if buf.Len() == 0 {
-		return nil
-	}
-
 	// Use wMu to ensure exclusive access to the underlying writer
 	bw.wMu.Lock()
 	defer bw.wMu.Unlock()
 
+	data := buf.Bytes()
+	retrying := bw.pending.Len() > 0
+	if retrying {
+		bw.pending.Write(data)
+		data = bw.pending.Bytes()
+	}
+	// Reset the buffer for next use, the data not written is kept in pending
+	defer buf.Reset()
+	if len(data) == 0 {
+		return nil
+	}
+
 	// Write data to the underlying writer
-	_, err := bw.w.Write(buf.Bytes())
-	if err != nil {
-		return err
+	n, err := bw.w.Write(data)
+	if err == nil && n < len(data) {
+		err = io.ErrShortWrite
 	}
+	switch {
+	case !retrying && err != nil:
+		bw.pending.Write(data[n:])
+	case retrying && err != nil:
+		bw.pending.Next(n)
+	case retrying:
+		// All data retained has been written, the error of it is out of date
+		bw.pending.Reset()
+		bw.err.take()
+	}
+	return err
+}
 
-	// Reset the buffer for next use
-	buf.Reset()
-	return nil
+// stickyError keeps the error occurred in background until it is taken.
+type stickyError struct {
+	mu  sync.Mutex
+	has atomic.Bool // Avoids locking when there is no error
+	err error
+}
+
+// set records the error of an asynchronous flush,
+// reports it to ErrorHandler and keeps it to be returned by the next Write of BufferedWriter.
+func (e *stickyError) set(err error) {
+	err = fmt.Errorf("failed to flush buffer: %w", err)
+	if ErrorHandler != nil {
+		ErrorHandler(err)
+	}
+	e.mu.Lock()
+	e.err = err
+	e.has.Store(true)
+	e.mu.Unlock()
+}
+
+// take returns the error if any and clears it.
+func (e *stickyError) take() error {
+	if !e.has.Load() {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	err := e.err
+	e.err = nil
+	e.has.Store(false)
+	return err
 }
@@ -0,0 +1,241 @@
+package rainbowlog
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rambollwong/rainbowcat/pool"
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+// concurrentQueueSize is the number of records that can be queued to ConcurrentBufferedWriter
+// before Write blocks waiting for the flusher.
+const concurrentQueueSize = 1024
+
+// ConcurrentBufferedWriter is a buffered writer designed for many goroutines logging at the same time.
+//
+// Unlike BufferedWriter, Write never waits for the underlying writer while holding a lock shared by all writers.
+// Each record is copied once into a pooled bytes slice and sent to a bounded MPSC queue,
+// a single flusher goroutine drains the queue in order into a batch and writes the batch
+// to the underlying writer when it reaches bufSize. Records queued while the flusher is writing
+// are batched together and written by the next write, so that a slow underlying writer
+// is called fewer times instead of blocking every writer behind it.
+// Write blocks only when the queue is full, that is, when the underlying writer cannot keep up.
+//
+// Records are written in the order in which their Write calls returned,
+// so records of the same goroutine are never reordered.
+// Data failed to be flushed is kept in memory in order and written before any newer data by the next flush,
+// the error is reported to ErrorHandler and returned by the next Write or Flush.
+// Flush and Close follow the same contract as BufferedWriter.
+//
+// Queuing a record costs more than copying it into the buffer of BufferedWriter,
+// so BufferedWriter is still preferred for fast underlying writers and few logging goroutines.
+type ConcurrentBufferedWriter struct {
+	mu            sync.RWMutex          // Held for reading while sending to the queue, for writing when closing
+	w             io.Writer             // Underlying writer, only accessed by the flusher until closed
+	bufSize       int                   // Size of the batch that triggers a write
+	queue         chan concurrentRecord // Records and control requests waiting for the flusher
+	pool          sync.Pool             // Pool of *[]byte holding copies of records
+	batch         []byte                // Data waiting to be written, only accessed by the flusher
+	retained      int                   // Length of the data at the head of batch failed to be written
+	flushInterval time.Duration         // Interval of flushing periodically, disabled if not positive
+	closed        bool                  // Flag indicating whether the writer is closed, guarded by mu
+	exitC         chan error            // Receives the result of the final write when the flusher exits
+	err           stickyError           // Error of the last failed asynchronous flush, returned by the next Write
+}
+
+// concurrentRecord is an element of the queue of ConcurrentBufferedWriter,
+// either a record to be written or a control request to be executed by the flusher.
+type concurrentRecord struct {
+	data *[]byte      // Copy of the record, nil for control requests
+	do   func() error // Control request executed by the flusher
+	done chan<- error // Receives the result of the control request
+}
+
+// NewConcurrentBufferedWriter creates a new concurrent buffered writer.
+// The parameter w is the underlying io.Writer, and bufSize is the size of data
+// written to the underlying writer at once.
+func NewConcurrentBufferedWriter(w io.Writer, bufSize int) *ConcurrentBufferedWriter {
+	return NewConcurrentBufferedWriterWithFlushInterval(w, bufSize, 0)
+}
+
+// NewConcurrentBufferedWriterWithFlushInterval creates a new concurrent buffered writer which flushes
+// the buffered data every flushInterval in background. If flushInterval is not positive, the periodic flush is disabled.
+// The flusher goroutine will be stopped when the writer is closed.
+func NewConcurrentBufferedWriterWithFlushInterval(
+	w io.Writer, bufSize int, flushInterval time.Duration,
+) *ConcurrentBufferedWriter {
+	bw := &ConcurrentBufferedWriter{
+		w:             w,
+		bufSize:       bufSize,
+		queue:         make(chan concurrentRecord, concurrentQueueSize),
+		batch:         make([]byte, 0, 2*bufSize),
+		flushInterval: flushInterval,
+		exitC:         make(chan error, 1),
+	}
+	bw.pool.New = func() any {
+		bz := make([]byte, 0, 256)
+		return &bz
+	}
+	go bw.flushLoop()
+	return bw
+}
+
+// ConcurrentBufferedLevelWriter creates a new LevelWriter that wraps the provided io.Writer
+// with a ConcurrentBufferedWriter.
+func ConcurrentBufferedLevelWriter(w io.Writer, bufSize int) LevelWriter {
+	return NewConcurrentBufferedWriter(w, bufSize)
+}
+
+// Write implements the io.Writer interface, queuing a copy of the data to be written by the flusher.
+// If a previous asynchronous flush failed, the data is still queued and the error of that flush is returned.
+func (bw *ConcurrentBufferedWriter) Write(bz []byte) (n int, err error) {
+	bw.mu.RLock()
+	defer bw.mu.RUnlock()
+
+	if bw.closed {
+		return 0, os.ErrClosed
+	}
+	flushErr := bw.err.take()
+	if len(bz) == 0 {
+		return 0, flushErr
+	}
+
+	data := bw.pool.Get().(*[]byte)
+	*data = append((*data)[:0], bz...)
+	bw.queue <- concurrentRecord{data: data}
+	return len(bz), flushErr
+}
+
+// WriteLevel implements the LevelWriter interface by ignoring the level.
+func (bw *ConcurrentBufferedWriter) WriteLevel(_ level.Level, bz []byte) (n int, err error) {
+	return bw.Write(bz)
+}
+
+// Flush forcibly flushes all data written before it to the underlying writer.
+// If any data is failed to be written, the error will be returned.
+func (bw *ConcurrentBufferedWriter) Flush() error {
+	return bw.request(bw.writeBatch)
+}
+
+// Close closes the writer, ensuring all data written before it returns is flushed.
+// If any data is failed to be flushed, the error will be returned and the writer is kept open.
+func (bw *ConcurrentBufferedWriter) Close() error {
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	bw.mu.Lock()
+	if bw.closed {
+		bw.mu.Unlock()
+		return nil
+	}
+	bw.closed = true
+	// No Write is sending to the queue as mu is held, the flusher writes the records
+	// queued after the flush above, then exits.
+	close(bw.queue)
+	bw.mu.Unlock()
+
+	return <-bw.exitC
+}
+
+// Reopen reopens the underlying writer if it implements the Reopener interface.
+// The data queued before Reopen that has not been written yet
+// will be written into the reopened file instead of the rotated one.
+func (bw *ConcurrentBufferedWriter) Reopen() error {
+	r, ok := bw.w.(Reopener)
+	if !ok {
+		return nil
+	}
+	return bw.request(r.Reopen)
+}
+
+// request sends a control request to the flusher and waits for its result.
+// If the writer is closed, no operation is performed.
+func (bw *ConcurrentBufferedWriter) request(do func() error) error {
+	done := make(chan error, 1)
+	bw.mu.RLock()
+	if bw.closed {
+		bw.mu.RUnlock()
+		return nil
+	}
+	bw.queue <- concurrentRecord{do: do, done: done}
+	bw.mu.RUnlock()
+	return <-done
+}
+
+// flushLoop is the flusher goroutine, which drains the queue until it is closed.
+func (bw *ConcurrentBufferedWriter) flushLoop() {
+	var tickC <-chan time.Time
+	if bw.flushInterval > 0 {
+		ticker := time.NewTicker(bw.flushInterval)
+		defer ticker.Stop()
+		tickC = ticker.C
+	}
+	for {
+		select {
+		case rec, ok := <-bw.queue:
+			if !ok {
+				bw.exitC <- bw.writeBatch()
+				return
+			}
+			bw.handle(rec)
+			// Take the records queued while the flusher was busy as well, so that they are written at once.
+			// The number is bounded to keep the batch from growing while writers keep up with the flusher.
+			for i := len(bw.queue); i > 0; i-- {
+				if rec, ok = <-bw.queue; !ok {
+					break
+				}
+				bw.handle(rec)
+			}
+			if len(bw.batch)-bw.retained >= bw.bufSize {
+				if err := bw.writeBatch(); err != nil {
+					bw.err.set(err)
+				}
+			}
+		case <-tickC:
+			if err := bw.writeBatch(); err != nil {
+				bw.err.set(err)
+			}
+		}
+	}
+}
+
+// handle appends the record to the batch, or executes the control request.
+func (bw *ConcurrentBufferedWriter) handle(rec concurrentRecord) {
+	if rec.data == nil {
+		rec.done <- rec.do()
+		return
+	}
+	bw.batch = append(bw.batch, *rec.data...)
+	// Do not keep copies of large records in the pool
+	if cap(*rec.data) <= pool.DefaultMaxBytesCap {
+		bw.pool.Put(rec.data)
+	}
+}
+
+// writeBatch writes the batch to the underlying writer.
+// The data not written is kept at the head of the batch, so that it will be written before any newer data
+// by the next write.
+func (bw *ConcurrentBufferedWriter) writeBatch() error {
+	if len(bw.batch) == 0 {
+		return nil
+	}
+	n, err := bw.w.Write(bw.batch)
+	if err == nil && n < len(bw.batch) {
+		err = io.ErrShortWrite
+	}
+	bw.batch = bw.batch[:copy(bw.batch, bw.batch[n:])]
+	if err != nil {
+		bw.retained = len(bw.batch)
+		return err
+	}
+	if bw.retained > 0 {
+		// All data retained has been written, the error of it is out of date
+		bw.retained = 0
+		bw.err.take()
+	}
+	return nil
+}
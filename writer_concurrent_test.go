@@ -0,0 +1,240 @@
+package rainbowlog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentBufferedWriter(t *testing.T) {
+	t.Run("BasicWrite", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriter(buf, 64)
+		defer bw.Close()
+
+		n, err := bw.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+
+		require.NoError(t, bw.Flush())
+		assert.Equal(t, "hello", buf.String())
+	})
+
+	t.Run("WriteWhenBatchIsFull", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriter(buf, 4)
+		defer bw.Close()
+
+		_, err := bw.Write([]byte("hello world"))
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool { return buf.String() == "hello world" }, time.Second, time.Millisecond)
+	})
+
+	t.Run("CopyWrittenData", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriter(buf, 64)
+		defer bw.Close()
+
+		record := []byte("hello")
+		_, err := bw.Write(record)
+		require.NoError(t, err)
+		copy(record, "world")
+		require.NoError(t, bw.Flush())
+		assert.Equal(t, "hello", buf.String())
+	})
+
+	t.Run("KeepOrderOfEachGoroutine", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriter(buf, 128)
+
+		const goroutines, records = 8, 1000
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < records; i++ {
+					line := fmt.Sprintf("%d %d\n", g, i)
+					if i%100 == 0 {
+						// records larger than the batch
+						line = fmt.Sprintf("%d %d %s\n", g, i, strings.Repeat("x", 200))
+					}
+					_, err := bw.Write([]byte(line))
+					assert.NoError(t, err)
+				}
+			}(g)
+		}
+		wg.Wait()
+		require.NoError(t, bw.Close())
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		require.Len(t, lines, goroutines*records)
+		next := make([]int, goroutines)
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			g, err := strconv.Atoi(fields[0])
+			require.NoError(t, err)
+			i, err := strconv.Atoi(fields[1])
+			require.NoError(t, err)
+			require.Equal(t, next[g], i)
+			next[g]++
+		}
+	})
+
+	t.Run("CloseDrainsConcurrentWrites", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriter(slowWriterTo{w: buf, latency: 100 * time.Microsecond}, 64)
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			written int
+		)
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					if _, err := bw.Write([]byte("message\n")); err != nil {
+						assert.ErrorIs(t, err, os.ErrClosed)
+						return
+					}
+					mu.Lock()
+					written++
+					mu.Unlock()
+				}
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, bw.Close())
+		wg.Wait()
+
+		// Every write returned before Close has been written
+		assert.Equal(t, written, strings.Count(buf.String(), "message\n"))
+	})
+
+	t.Run("CloseFlushesBuffer", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriter(buf, 64)
+
+		_, err := bw.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, bw.Close())
+		assert.Equal(t, "hello", buf.String())
+
+		_, err = bw.Write([]byte("world"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+		assert.NoError(t, bw.Flush())
+		assert.NoError(t, bw.Close())
+	})
+
+	t.Run("CloseKeepsWriterOpenOnError", func(t *testing.T) {
+		w := &switchableWriter{broken: true}
+		bw := NewConcurrentBufferedWriter(w, 64)
+
+		_, err := bw.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.ErrorIs(t, bw.Close(), io.ErrClosedPipe)
+
+		w.broken = false
+		_, err = bw.Write([]byte(" world"))
+		require.NoError(t, err)
+		require.NoError(t, bw.Close())
+		assert.Equal(t, "hello world", w.String())
+	})
+
+	t.Run("FlushPeriodically", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriterWithFlushInterval(buf, 64, 10*time.Millisecond)
+		defer bw.Close()
+
+		_, err := bw.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool { return buf.String() == "hello" }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("RetryInOrderAfterFlushError", func(t *testing.T) {
+		errorHandler := ErrorHandler
+		defer func() { ErrorHandler = errorHandler }()
+		ErrorHandler = func(err error) {}
+
+		w := &switchableWriter{broken: true}
+		bw := NewConcurrentBufferedWriter(w, 5)
+		_, err := bw.Write([]byte("AAAAA"))
+		require.NoError(t, err)
+		require.Eventually(t, bw.err.has.Load, time.Second, time.Millisecond)
+
+		w.broken = false
+		_, err = bw.Write([]byte("BBBBB"))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		_, err = bw.Write([]byte("C"))
+		assert.NoError(t, err)
+		assert.NoError(t, bw.Close())
+		assert.Equal(t, "AAAAABBBBBC", w.String())
+	})
+
+	t.Run("RetryAfterShortWrite", func(t *testing.T) {
+		w := &shortWriter{short: 2}
+		bw := NewConcurrentBufferedWriter(w, 64)
+		_, err := bw.Write([]byte("hello"))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, bw.Flush(), io.ErrShortWrite)
+		_, err = bw.Write([]byte(" world"))
+		require.NoError(t, err)
+		assert.NoError(t, bw.Close())
+		assert.Equal(t, "hello world", w.String())
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "test.log")
+		w, err := NewReopenFileWriter(filePath, 0)
+		require.NoError(t, err)
+		bw := NewConcurrentBufferedWriter(w, 64)
+
+		_, err = bw.Write([]byte("buffered"))
+		require.NoError(t, err)
+		require.NoError(t, os.Rename(filePath, filePath+".1"))
+		require.NoError(t, bw.Reopen())
+		require.NoError(t, bw.Close())
+		require.NoError(t, w.Close())
+
+		rotated, err := os.ReadFile(filePath + ".1")
+		require.NoError(t, err)
+		assert.Empty(t, rotated)
+		current, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "buffered", string(current))
+	})
+
+	t.Run("WithLogger", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewConcurrentBufferedWriter(buf, 4096)
+		logger := New(AppendsEncoderWriters(JsonEnc, bw))
+
+		logger.Info().Msg("hello").Done()
+		require.NoError(t, bw.Close())
+		assert.Contains(t, buf.String(), `"message":"hello"`)
+	})
+}
+
+// slowWriterTo is a test helper that writes to w with the latency of disk I/O.
+type slowWriterTo struct {
+	w       io.Writer
+	latency time.Duration
+}
+
+func (w slowWriterTo) Write(bz []byte) (int, error) {
+	time.Sleep(w.latency)
+	return w.w.Write(bz)
+}
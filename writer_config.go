@@ -0,0 +1,231 @@
+package rainbowlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rambollwong/rainbowcat/util"
+	"github.com/rambollwong/rainbowcat/writer/filewriter"
+)
+
+const (
+	WriterTypeStdout        = "stdout"
+	WriterTypeStderr        = "stderr"
+	WriterTypeFile          = "file"
+	WriterTypeSizeRolling   = "sizeRolling"
+	WriterTypeTimeRolling   = "timeRolling"
+	WriterTypeHybridRolling = "hybridRolling"
+	WriterTypeSyslog        = "syslog"
+	WriterTypeNetwork       = "network"
+)
+
+// WriterFactory creates a LevelWriter with the type-specific options of a writer config.
+// Options are decoded from config files, so keys may be in lower case and values may be strings,
+// DecodeWriterOptions helps to decode them into a struct.
+type WriterFactory func(options map[string]any) (LevelWriter, error)
+
+var (
+	writerFactoriesMu sync.RWMutex
+	writerFactories   = map[string]WriterFactory{
+		strings.ToLower(WriterTypeStdout):        newStdoutWriter,
+		strings.ToLower(WriterTypeStderr):        newStderrWriter,
+		strings.ToLower(WriterTypeFile):          newFileWriter,
+		strings.ToLower(WriterTypeSizeRolling):   newSizeRollingWriter,
+		strings.ToLower(WriterTypeTimeRolling):   newTimeRollingWriter,
+		strings.ToLower(WriterTypeHybridRolling): newHybridRollingWriter,
+		strings.ToLower(WriterTypeNetwork):       newNetworkWriter,
+	}
+)
+
+// RegisterWriterFactory registers a factory for the writer type, which is case-insensitive,
+// so that writers of the type can be created by the writers list of config.
+// Registering a type that has been registered replaces the previous factory, built-in types included.
+func RegisterWriterFactory(writerType string, factory WriterFactory) {
+	writerFactoriesMu.Lock()
+	defer writerFactoriesMu.Unlock()
+	writerFactories[strings.ToLower(writerType)] = factory
+}
+
+// NewWriterByType creates a LevelWriter of the writer type with the factory registered.
+func NewWriterByType(writerType string, options map[string]any) (LevelWriter, error) {
+	writerFactoriesMu.RLock()
+	factory, ok := writerFactories[strings.ToLower(writerType)]
+	writerFactoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown writer type: %s", writerType)
+	}
+	return factory(options)
+}
+
+// DecodeWriterOptions decodes options of a writer config into the struct pointed by out,
+// matching keys to `mapstructure` tags or field names case-insensitively.
+// Values are converted weakly, e.g. "10" to 10 and "1s" to time.Second.
+// An error is returned if any option is unknown.
+func DecodeWriterOptions(options map[string]any, out any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(options)
+}
+
+// fileWriterOptions are options of the file writer.
+type fileWriterOptions struct {
+	LogFilePath     string        `mapstructure:"logFilePath"`
+	LogFileBaseName string        `mapstructure:"logFileBaseName"`
+	CheckInterval   time.Duration `mapstructure:"checkInterval"`
+}
+
+// rollingWriterOptions are options of the rolling file writers.
+type rollingWriterOptions struct {
+	LogFilePath     string                   `mapstructure:"logFilePath"`
+	LogFileBaseName string                   `mapstructure:"logFileBaseName"`
+	RollingPeriod   filewriter.RollingPeriod `mapstructure:"rollingPeriod"`
+	FileSizeLimit   string                   `mapstructure:"fileSizeLimit"`
+	MaxBackups      int                      `mapstructure:"maxBackups"`
+	MaxAge          time.Duration            `mapstructure:"maxAge"`
+	MaxTotalSize    string                   `mapstructure:"maxTotalSize"`
+	Compress        string                   `mapstructure:"compress"`
+}
+
+// networkWriterOptions are options of the network writer.
+type networkWriterOptions struct {
+	Network     string        `mapstructure:"network"`
+	Address     string        `mapstructure:"address"`
+	DialTimeout time.Duration `mapstructure:"dialTimeout"`
+}
+
+func newStdoutWriter(options map[string]any) (LevelWriter, error) {
+	if err := DecodeWriterOptions(options, &struct{}{}); err != nil {
+		return nil, err
+	}
+	return LevelWriterAdapter(stdWriter{os.Stdout}), nil
+}
+
+func newStderrWriter(options map[string]any) (LevelWriter, error) {
+	if err := DecodeWriterOptions(options, &struct{}{}); err != nil {
+		return nil, err
+	}
+	return LevelWriterAdapter(stdWriter{os.Stderr}), nil
+}
+
+// stdWriter hides the Close method of os.Stdout and os.Stderr,
+// so that they are not closed together with writers created by config.
+type stdWriter struct {
+	io.Writer
+}
+
+// newFileWriter creates a ReopenFileWriter, which works with external log rotating tools.
+func newFileWriter(options map[string]any) (LevelWriter, error) {
+	opts := fileWriterOptions{}
+	if err := DecodeWriterOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.LogFileBaseName == "" {
+		return nil, errors.New("logFileBaseName is required")
+	}
+	if opts.LogFilePath != "" {
+		if err := os.MkdirAll(opts.LogFilePath, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return NewReopenFileWriter(filepath.Join(opts.LogFilePath, opts.LogFileBaseName), opts.CheckInterval)
+}
+
+func newSizeRollingWriter(options map[string]any) (LevelWriter, error) {
+	opts, sizeLimit, totalSize, compression, err := decodeRollingWriterOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if opts.RollingPeriod != "" || opts.MaxAge != 0 || totalSize != 0 {
+		return nil, errors.New("rollingPeriod, maxAge and maxTotalSize are not supported by size rolling writer")
+	}
+	return NewSizeRollingFileWriter(opts.LogFilePath, opts.LogFileBaseName, opts.MaxBackups, sizeLimit, compression)
+}
+
+func newTimeRollingWriter(options map[string]any) (LevelWriter, error) {
+	opts, sizeLimit, totalSize, compression, err := decodeRollingWriterOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if sizeLimit != 0 || opts.MaxAge != 0 || totalSize != 0 {
+		return nil, errors.New("fileSizeLimit, maxAge and maxTotalSize are not supported by time rolling writer")
+	}
+	return NewTimeRollingFileWriter(opts.LogFilePath, opts.LogFileBaseName, opts.MaxBackups, opts.RollingPeriod, compression)
+}
+
+func newHybridRollingWriter(options map[string]any) (LevelWriter, error) {
+	opts, sizeLimit, totalSize, compression, err := decodeRollingWriterOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return NewHybridRollingFileWriter(
+		opts.LogFilePath,
+		opts.LogFileBaseName,
+		opts.RollingPeriod,
+		sizeLimit,
+		opts.MaxBackups,
+		opts.MaxAge,
+		totalSize,
+		compression,
+	)
+}
+
+// decodeRollingWriterOptions decodes options of rolling file writers and parses sizes and compression of them.
+// The max backups is unlimited if not set.
+func decodeRollingWriterOptions(options map[string]any) (
+	opts rollingWriterOptions, sizeLimit, totalSize int64, compression Compression, err error,
+) {
+	opts.MaxBackups = -1
+	if err = DecodeWriterOptions(options, &opts); err != nil {
+		return
+	}
+	if opts.LogFileBaseName == "" {
+		err = errors.New("logFileBaseName is required")
+		return
+	}
+	if opts.FileSizeLimit != "" {
+		if sizeLimit, err = util.ParseToBytesSize(opts.FileSizeLimit, 1024); err != nil {
+			err = fmt.Errorf("wrong file size limit: %s", opts.FileSizeLimit)
+			return
+		}
+	}
+	if opts.MaxTotalSize != "" {
+		if totalSize, err = util.ParseToBytesSize(opts.MaxTotalSize, 1024); err != nil {
+			err = fmt.Errorf("wrong max total size: %s", opts.MaxTotalSize)
+			return
+		}
+	}
+	compression, err = ParseCompression(opts.Compress)
+	return
+}
+
+// newNetworkWriter dials the address and writes records to the connection,
+// e.g. "tcp" or "udp" to a log collector, or "unix" to a local socket.
+func newNetworkWriter(options map[string]any) (LevelWriter, error) {
+	opts := networkWriterOptions{Network: "tcp"}
+	if err := DecodeWriterOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	if opts.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	conn, err := net.DialTimeout(opts.Network, opts.Address, opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return LevelWriterAdapter(conn), nil
+}
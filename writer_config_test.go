@@ -0,0 +1,140 @@
+package rainbowlog
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterFactory(t *testing.T) {
+	t.Run("DecodeWriterOptions", func(t *testing.T) {
+		opts := struct {
+			LogFileBaseName string        `mapstructure:"logFileBaseName"`
+			MaxBackups      int           `mapstructure:"maxBackups"`
+			CheckInterval   time.Duration `mapstructure:"checkInterval"`
+		}{}
+		// keys are converted to lower case when loading config files
+		require.NoError(t, DecodeWriterOptions(map[string]any{
+			"logfilebasename": "app.log",
+			"maxbackups":      "3",
+			"checkinterval":   "1s",
+		}, &opts))
+		assert.Equal(t, "app.log", opts.LogFileBaseName)
+		assert.Equal(t, 3, opts.MaxBackups)
+		assert.Equal(t, time.Second, opts.CheckInterval)
+
+		assert.Error(t, DecodeWriterOptions(map[string]any{"unknown": 1}, &opts))
+	})
+
+	t.Run("RegisterWriterFactory", func(t *testing.T) {
+		buffers := make(map[string]*bytes.Buffer)
+		RegisterWriterFactory("memory", func(options map[string]any) (LevelWriter, error) {
+			opts := struct{ Name string }{}
+			if err := DecodeWriterOptions(options, &opts); err != nil {
+				return nil, err
+			}
+			buffers[opts.Name] = &bytes.Buffer{}
+			return LevelWriterAdapter(buffers[opts.Name]), nil
+		})
+
+		w, err := NewWriterByType("MEMORY", map[string]any{"name": "a"})
+		require.NoError(t, err)
+		_, err = w.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", buffers["a"].String())
+
+		_, err = NewWriterByType("memory", map[string]any{"size": 1})
+		assert.Error(t, err)
+		_, err = NewWriterByType("unknown", nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("NetworkWriter", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+		received := make(chan string, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			received <- line
+		}()
+
+		w, err := NewWriterByType(WriterTypeNetwork, map[string]any{"address": ln.Addr().String(), "dialTimeout": "1s"})
+		require.NoError(t, err)
+		defer unwrapLevelWriter(w).(net.Conn).Close()
+		_, err = w.WriteLevel(level.Info, []byte("hello\n"))
+		require.NoError(t, err)
+		select {
+		case line := <-received:
+			assert.Equal(t, "hello\n", line)
+		case <-time.After(5 * time.Second):
+			t.Fatal("nothing received")
+		}
+	})
+
+	t.Run("WithConfig", func(t *testing.T) {
+		dir := t.TempDir()
+		var all bytes.Buffer
+		RegisterWriterFactory("testBuffer", func(map[string]any) (LevelWriter, error) {
+			return LevelWriterAdapter(&all), nil
+		})
+		cfg := config.DefaultLoggerConfig()
+		cfg.EnableConsolePrinting = false
+		cfg.Writers = []config.LoggerWriterConfig{
+			{Enable: true, Type: "testBuffer", Encoder: "text"},
+			{
+				Enable:            true,
+				Type:              WriterTypeFile,
+				Encoder:           "text",
+				MinLevel:          "ERROR",
+				UseBufferedWriter: true,
+				WriterBufferSize:  "4K",
+				Options:           map[string]any{"logFilePath": dir, "logFileBaseName": "error.log"},
+			},
+			{
+				Enable:   true,
+				Type:     WriterTypeSizeRolling,
+				Encoder:  "text",
+				MinLevel: "WARN",
+				Options:  map[string]any{"logFilePath": dir, "logFileBaseName": "warn.log", "fileSizeLimit": "1M"},
+			},
+			{Enable: false, Type: "unknown"},
+		}
+		logger := New(WithDefault(), WithConfig(cfg), WithMetaKeys())
+		closeLoggerWriters(t, logger)
+
+		logger.Info().Msg("info").Done()
+		logger.Warn().Msg("warn").Done()
+		logger.Error().Msg("error").Done()
+		require.NoError(t, logger.Flush())
+
+		assert.Equal(t, "message=info\nmessage=warn\nmessage=error\n", all.String())
+		for file, content := range map[string]string{
+			"error.log": "message=error\n",
+			"warn.log":  "message=warn\nmessage=error\n",
+		} {
+			bz, err := os.ReadFile(filepath.Join(dir, file))
+			require.NoError(t, err)
+			assert.Equal(t, content, string(bz), file)
+		}
+
+		cfg.Writers = []config.LoggerWriterConfig{{Enable: true, Type: "unknown"}}
+		assert.Panics(t, func() { New(WithConfig(cfg)) })
+		cfg.Writers = []config.LoggerWriterConfig{{Enable: true, Type: "testBuffer", MinLevel: "verbose"}}
+		assert.Panics(t, func() { New(WithConfig(cfg)) })
+	})
+}
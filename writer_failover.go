@@ -0,0 +1,199 @@
+package rainbowlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var _ LevelWriter = (*FailoverWriter)(nil)
+
+// FailoverStats shows how many records went where in a FailoverWriter.
+// The indexes of Written and Failed follow the order of writers, the primary first.
+type FailoverStats struct {
+	Written []uint64 // Number of records written by each writer
+	Failed  []uint64 // Number of failed writing of each writer
+	Dropped uint64   // Number of records failed on all writers
+}
+
+// writerHealth tracks the health of a writer in the circuit breaker way.
+type writerHealth struct {
+	failures  int       // Consecutive failures
+	openUntil time.Time // Writer will be skipped until this time if the circuit is open
+	written   uint64
+	failed    uint64
+}
+
+// FailoverWriter writes each record to the primary writer, and falls back to the secondary writers
+// in order when the primary fails, e.g. a network writer falling back to a local file then os.Stderr.
+//
+// Each writer is tracked like a circuit breaker: after failureThreshold consecutive failures,
+// the writer will be skipped for retryInterval, then it will be tried again,
+// and records will return to it automatically once it succeeds.
+type FailoverWriter struct {
+	mu               sync.Mutex
+	writers          []LevelWriter
+	healths          []writerHealth
+	dropped          uint64
+	failureThreshold int
+	retryInterval    time.Duration
+}
+
+// NewFailoverWriter creates a new FailoverWriter.
+//
+//	params:
+//		- failureThreshold: defines the number of consecutive failures that makes a writer be skipped.
+//			If it is not positive, 1 will be used.
+//		- retryInterval: defines the duration to skip a failing writer before trying it again.
+//		- primary: defines the writer preferred.
+//		- secondaries: define the writers to fall back to in order.
+func NewFailoverWriter(
+	failureThreshold int,
+	retryInterval time.Duration,
+	primary io.Writer,
+	secondaries ...io.Writer,
+) *FailoverWriter {
+	if failureThreshold <= 0 {
+		failureThreshold = 1
+	}
+	writers := make([]LevelWriter, 0, len(secondaries)+1)
+	writers = append(writers, LevelWriterAdapter(primary))
+	for _, w := range secondaries {
+		writers = append(writers, LevelWriterAdapter(w))
+	}
+	return &FailoverWriter{
+		writers:          writers,
+		healths:          make([]writerHealth, len(writers)),
+		failureThreshold: failureThreshold,
+		retryInterval:    retryInterval,
+	}
+}
+
+// Write implements the io.Writer interface.
+func (f *FailoverWriter) Write(bz []byte) (n int, err error) {
+	return f.write(bz, func(w LevelWriter) (int, error) {
+		return w.Write(bz)
+	})
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (f *FailoverWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
+	return f.write(bz, func(w LevelWriter) (int, error) {
+		return w.WriteLevel(lv, bz)
+	})
+}
+
+// write tries the writers in order until one of them succeeds.
+// If all writers fail, the errors of them will be joined and returned.
+func (f *FailoverWriter) write(bz []byte, writeFunc func(w LevelWriter) (int, error)) (int, error) {
+	var errs []error
+	for i, w := range f.writers {
+		if !f.available(i) {
+			continue
+		}
+		n, err := writeFunc(w)
+		if err == nil && n != len(bz) {
+			err = io.ErrShortWrite
+		}
+		if f.report(i, err) && ErrorHandler != nil {
+			ErrorHandler(fmt.Errorf("failover writer #%d is skipped for %s: %w", i, f.retryInterval, err))
+		}
+		if err == nil {
+			return n, nil
+		}
+		errs = append(errs, fmt.Errorf("failover writer #%d: %w", i, err))
+	}
+	f.mu.Lock()
+	f.dropped++
+	f.mu.Unlock()
+	if len(errs) == 0 {
+		return 0, errors.New("failover writer: no writer available")
+	}
+	return 0, errors.Join(errs...)
+}
+
+// available checks whether the writer of the index should be tried.
+func (f *FailoverWriter) available(i int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !time.Now().Before(f.healths[i].openUntil)
+}
+
+// report updates the health of the writer of the index by the result of writing.
+// Returns true if the writer becomes skipped.
+func (f *FailoverWriter) report(i int, err error) (skipped bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := &f.healths[i]
+	if err == nil {
+		h.written++
+		h.failures = 0
+		h.openUntil = time.Time{}
+		return false
+	}
+	h.failed++
+	h.failures++
+	if h.failures >= f.failureThreshold {
+		h.openUntil = time.Now().Add(f.retryInterval)
+		return true
+	}
+	return false
+}
+
+// Stats returns the statistics of records written.
+func (f *FailoverWriter) Stats() FailoverStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stats := FailoverStats{
+		Written: make([]uint64, len(f.healths)),
+		Failed:  make([]uint64, len(f.healths)),
+		Dropped: f.dropped,
+	}
+	for i, h := range f.healths {
+		stats.Written[i] = h.written
+		stats.Failed[i] = h.failed
+	}
+	return stats
+}
+
+// Flush flushes all writers that implement the Flush() method.
+// Returns the first error encountered.
+func (f *FailoverWriter) Flush() (err error) {
+	for _, w := range f.writers {
+		if fw, ok := unwrapLevelWriter(w).(interface{ Flush() error }); ok {
+			if e := fw.Flush(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// Close closes all writers that implement the io.Closer interface.
+// Returns the first error encountered.
+func (f *FailoverWriter) Close() (err error) {
+	for _, w := range f.writers {
+		if c, ok := unwrapLevelWriter(w).(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// unwrapLevelWriter returns the io.Writer wrapped by levelWriterAdapter if any.
+func unwrapLevelWriter(w LevelWriter) io.Writer {
+	switch lw := w.(type) {
+	case levelWriterAdapter:
+		return lw.Writer
+	case *levelWriterAdapter:
+		return lw.Writer
+	default:
+		return w
+	}
+}
@@ -0,0 +1,75 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// switchableWriter is a test helper that fails on writing when broken.
+type switchableWriter struct {
+	bytes.Buffer
+	broken bool
+}
+
+func (w *switchableWriter) Write(bz []byte) (int, error) {
+	if w.broken {
+		return 0, io.ErrClosedPipe
+	}
+	return w.Buffer.Write(bz)
+}
+
+func TestFailoverWriter(t *testing.T) {
+	errorHandler := ErrorHandler
+	defer func() { ErrorHandler = errorHandler }()
+	ErrorHandler = func(err error) {}
+
+	t.Run("FallbackAndReturnToPrimary", func(t *testing.T) {
+		primary := &switchableWriter{}
+		secondary := &bytes.Buffer{}
+		w := NewFailoverWriter(2, 50*time.Millisecond, primary, secondary)
+
+		_, err := w.WriteLevel(level.Info, []byte("1"))
+		require.NoError(t, err)
+
+		primary.broken = true
+		for _, s := range []string{"2", "3", "4"} {
+			_, err = w.WriteLevel(level.Info, []byte(s))
+			require.NoError(t, err)
+		}
+		// primary skipped after 2 failures, so only 2 failed writing on it
+		assert.Equal(t, FailoverStats{Written: []uint64{1, 3}, Failed: []uint64{2, 0}}, w.Stats())
+
+		// primary is still skipped even though it recovers
+		primary.broken = false
+		_, err = w.WriteLevel(level.Info, []byte("5"))
+		require.NoError(t, err)
+		assert.Equal(t, "2345", secondary.String())
+
+		time.Sleep(60 * time.Millisecond)
+		_, err = w.WriteLevel(level.Info, []byte("6"))
+		require.NoError(t, err)
+		assert.Equal(t, "16", primary.String())
+		assert.Equal(t, "2345", secondary.String())
+	})
+
+	t.Run("AllFailed", func(t *testing.T) {
+		w := NewFailoverWriter(1, time.Minute, &errorWriter{}, &switchableWriter{broken: true})
+
+		_, err := w.Write([]byte("hello"))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+		assert.True(t, errors.Is(err, io.ErrClosedPipe))
+
+		// all writers skipped
+		_, err = w.Write([]byte("hello"))
+		require.Error(t, err)
+		assert.Equal(t, uint64(2), w.Stats().Dropped)
+	})
+}
@@ -0,0 +1,208 @@
+package rainbowlog
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var (
+	_ LevelWriter = (*FanOutWriter)(nil)
+	_ LevelWriter = (*minLevelWriter)(nil)
+)
+
+// WriterError describes the error of a writer in a group of writers.
+type WriterError struct {
+	Index int   // Index of the failed writer
+	Err   error // Error returned by the failed writer
+}
+
+// MultiWriteError is returned when some of a group of writers failed to write.
+type MultiWriteError struct {
+	Total    int           // Number of writers written to
+	Failures []WriterError // Failed writers in order
+}
+
+// Error implements the error interface.
+func (e *MultiWriteError) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString(strconv.Itoa(len(e.Failures)))
+	sb.WriteString(" of ")
+	sb.WriteString(strconv.Itoa(e.Total))
+	sb.WriteString(" writers failed: ")
+	for i, f := range e.Failures {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString("writer #")
+		sb.WriteString(strconv.Itoa(f.Index))
+		sb.WriteString(": ")
+		sb.WriteString(f.Err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the errors of failed writers, so that errors.Is and errors.As work with them.
+func (e *MultiWriteError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// FanOutWriter duplicates its writes to all the provided writers.
+// Different from MultiLevelWriter, it is able to write to writers in parallel,
+// so that a slow writer does not delay the others.
+// Writers wrapped by MinLevelWriter are able to filter records by level individually.
+type FanOutWriter struct {
+	writers  []LevelWriter
+	parallel bool
+}
+
+// NewFanOutWriter creates a new FanOutWriter.
+// If parallel is true, each writing will be performed on all writers concurrently,
+// and it returns after all writers have finished.
+// Each provided writer is wrapped with LevelWriterAdapter to ensure
+// it implements the LevelWriter interface.
+func NewFanOutWriter(parallel bool, writers ...io.Writer) *FanOutWriter {
+	levelWriters := make([]LevelWriter, 0, len(writers))
+	for _, w := range writers {
+		levelWriters = append(levelWriters, LevelWriterAdapter(w))
+	}
+	return &FanOutWriter{writers: levelWriters, parallel: parallel}
+}
+
+// Write implements the io.Writer interface by writing to all underlying writers.
+// A failed writer does not stop writing to the others,
+// a *MultiWriteError describing the failed writers will be returned if any.
+func (f *FanOutWriter) Write(bz []byte) (n int, err error) {
+	return writeAll(f.writers, f.parallel, bz, func(w LevelWriter) (int, error) {
+		return w.Write(bz)
+	})
+}
+
+// WriteLevel implements the LevelWriter interface by writing to all underlying writers.
+// A failed writer does not stop writing to the others,
+// a *MultiWriteError describing the failed writers will be returned if any.
+func (f *FanOutWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
+	return writeAll(f.writers, f.parallel, bz, func(w LevelWriter) (int, error) {
+		return w.WriteLevel(lv, bz)
+	})
+}
+
+// Flush flushes all writers that implement the Flush() method.
+// Returns the first error encountered.
+func (f *FanOutWriter) Flush() (err error) {
+	for _, w := range f.writers {
+		if fw, ok := unwrapLevelWriter(w).(interface{ Flush() error }); ok {
+			if e := fw.Flush(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// Close closes all writers that implement the io.Closer interface.
+// Returns the first error encountered.
+func (f *FanOutWriter) Close() (err error) {
+	for _, w := range f.writers {
+		if c, ok := unwrapLevelWriter(w).(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// writeAll writes to all writers by the write function given, sequentially or in parallel.
+// It returns the least number of bytes written by writers,
+// and a *MultiWriteError if any writer failed or performed a short write.
+func writeAll(writers []LevelWriter, parallel bool, bz []byte, write func(w LevelWriter) (int, error)) (int, error) {
+	ns := make([]int, len(writers))
+	errs := make([]error, len(writers))
+	writeOne := func(i int) {
+		ns[i], errs[i] = write(writers[i])
+		if errs[i] == nil && ns[i] != len(bz) {
+			errs[i] = io.ErrShortWrite
+		}
+	}
+	if parallel && len(writers) > 1 {
+		var wg sync.WaitGroup
+		for i := 1; i < len(writers); i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				writeOne(i)
+			}(i)
+		}
+		writeOne(0)
+		wg.Wait()
+	} else {
+		for i := range writers {
+			writeOne(i)
+		}
+	}
+
+	n := len(bz)
+	var mErr *MultiWriteError
+	for i, err := range errs {
+		if ns[i] < n {
+			n = ns[i]
+		}
+		if err == nil {
+			continue
+		}
+		if mErr == nil {
+			mErr = &MultiWriteError{Total: len(writers)}
+		}
+		mErr.Failures = append(mErr.Failures, WriterError{Index: i, Err: err})
+	}
+	if mErr != nil {
+		return n, mErr
+	}
+	return n, nil
+}
+
+// minLevelWriter is a wrapper that discards records below the minimum level.
+type minLevelWriter struct {
+	LevelWriter
+	minLevel level.Level
+}
+
+// MinLevelWriter wraps a writer to discard records whose level is below minLevel.
+// Writing without level by Write method is never discarded.
+// It is useful for filtering records of each writer in a FanOutWriter or a MultiLevelWriter.
+func MinLevelWriter(w io.Writer, minLevel level.Level) LevelWriter {
+	return &minLevelWriter{LevelWriter: LevelWriterAdapter(w), minLevel: minLevel}
+}
+
+// WriteLevel implements the LevelWriter interface.
+// Records below the minimum level are discarded, but reported as written.
+func (m *minLevelWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
+	if lv < m.minLevel {
+		return len(bz), nil
+	}
+	return m.LevelWriter.WriteLevel(lv, bz)
+}
+
+// Flush flushes the underlying writer if it implements the Flush() method.
+func (m *minLevelWriter) Flush() error {
+	if fw, ok := unwrapLevelWriter(m.LevelWriter).(interface{ Flush() error }); ok {
+		return fw.Flush()
+	}
+	return nil
+}
+
+// Close closes the underlying writer if it implements the io.Closer interface.
+func (m *minLevelWriter) Close() error {
+	if c, ok := unwrapLevelWriter(m.LevelWriter).(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOutWriter(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		name := "Sequential"
+		if parallel {
+			name = "Parallel"
+		}
+		t.Run(name, func(t *testing.T) {
+			buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+			w := NewFanOutWriter(parallel, buf1, &errorWriter{}, buf2)
+
+			n, err := w.WriteLevel(level.Info, []byte("hello"))
+			require.Error(t, err)
+			assert.Equal(t, 0, n)
+			// a failed writer does not stop writing to the others
+			assert.Equal(t, "hello", buf1.String())
+			assert.Equal(t, "hello", buf2.String())
+
+			var mErr *MultiWriteError
+			require.True(t, errors.As(err, &mErr))
+			assert.Equal(t, 3, mErr.Total)
+			assert.Equal(t, []WriterError{{Index: 1, Err: io.ErrUnexpectedEOF}}, mErr.Failures)
+			assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+			assert.Equal(t, "1 of 3 writers failed: writer #1: unexpected EOF", err.Error())
+		})
+	}
+
+	t.Run("AllSucceeded", func(t *testing.T) {
+		buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+		w := NewFanOutWriter(true, buf1, buf2)
+
+		n, err := w.Write([]byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+		assert.Equal(t, "hello", buf1.String())
+		assert.Equal(t, "hello", buf2.String())
+	})
+
+	t.Run("MinLevelWriter", func(t *testing.T) {
+		all, errOnly := &bytes.Buffer{}, &bytes.Buffer{}
+		logger := New(
+			AppendsEncoderWriters(TextEnc, NewFanOutWriter(true, all, MinLevelWriter(errOnly, level.Error))),
+			WithMetaKeys(),
+		)
+
+		logger.Info().Msg("info").Done()
+		logger.Error().Msg("error").Done()
+		assert.Equal(t, "message=info\nmessage=error\n", all.String())
+		assert.Equal(t, "message=error\n", errOnly.String())
+	})
+}
@@ -0,0 +1,202 @@
+package rainbowlog
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var (
+	_ LabelLevelWriter = (*LabelRouteWriter)(nil)
+	_ LabelLevelWriter = multiLevelWriter{}
+	_ LabelLevelWriter = (*FanOutWriter)(nil)
+	_ LabelLevelWriter = (*minLevelWriter)(nil)
+)
+
+// LabelLevelWriter is an interface for writers which need the label of each record, e.g. routing records by label.
+// If the writer of a logger implements it, WriteLabelLevel will be called instead of WriteLevel.
+//
+// NOTICE: the label will be lost if the writer is wrapped by a writer not implementing it, e.g. BufferedWriter,
+// so such writers should be wrapped by the label writer instead.
+type LabelLevelWriter interface {
+	LevelWriter
+	WriteLabelLevel(label string, lv level.Level, bz []byte) (n int, err error)
+}
+
+// writeLabelLevel writes to the writer with the label if it implements LabelLevelWriter,
+// otherwise writes with the level only.
+func writeLabelLevel(w LevelWriter, label string, lv level.Level, bz []byte) (n int, err error) {
+	if lw, ok := w.(LabelLevelWriter); ok {
+		return lw.WriteLabelLevel(label, lv, bz)
+	}
+	return w.WriteLevel(lv, bz)
+}
+
+// WriteLabelLevel implements the LabelLevelWriter interface by writing to all underlying writers.
+func (t multiLevelWriter) WriteLabelLevel(label string, lv level.Level, bz []byte) (n int, err error) {
+	return writeAll(t.writers, false, bz, func(w LevelWriter) (int, error) {
+		return writeLabelLevel(w, label, lv, bz)
+	})
+}
+
+// WriteLabelLevel implements the LabelLevelWriter interface by writing to all underlying writers.
+func (f *FanOutWriter) WriteLabelLevel(label string, lv level.Level, bz []byte) (n int, err error) {
+	return writeAll(f.writers, f.parallel, bz, func(w LevelWriter) (int, error) {
+		return writeLabelLevel(w, label, lv, bz)
+	})
+}
+
+// WriteLabelLevel implements the LabelLevelWriter interface.
+// Records below the minimum level are discarded, but reported as written.
+func (m *minLevelWriter) WriteLabelLevel(label string, lv level.Level, bz []byte) (n int, err error) {
+	if lv < m.minLevel {
+		return len(bz), nil
+	}
+	return writeLabelLevel(m.LevelWriter, label, lv, bz)
+}
+
+// labelPrefixRoute is a route matching labels by prefix.
+type labelPrefixRoute struct {
+	prefix string
+	writer LevelWriter
+}
+
+// LabelRouteWriter routes records to different writers by the label of them,
+// e.g. records labeled "audit" go to audit.log, records labeled "db" or "db|..." go to db.log.
+// Records not matching any route and records written without label go to the default writer.
+//
+// A route with a pattern ending with "*" matches labels by the prefix before "*",
+// besides, a pattern ending with "|*" also matches the label without "|",
+// e.g. "db|*" matches "db", "db|query" and "db|query|slow". Other patterns match labels exactly.
+// An exact route takes precedence over prefix routes, and the longest prefix wins among prefix routes.
+//
+// Routes should be added before logging, adding routes is not thread-safe.
+type LabelRouteWriter struct {
+	exactRoutes   map[string]LevelWriter
+	prefixRoutes  []labelPrefixRoute
+	defaultWriter LevelWriter
+}
+
+// NewLabelRouteWriter creates a new LabelRouteWriter.
+// If defaultWriter is nil, records not routed will be discarded.
+func NewLabelRouteWriter(defaultWriter io.Writer) *LabelRouteWriter {
+	r := &LabelRouteWriter{
+		exactRoutes: make(map[string]LevelWriter),
+	}
+	if defaultWriter != nil {
+		r.defaultWriter = LevelWriterAdapter(defaultWriter)
+	}
+	return r
+}
+
+// Route adds a route of the pattern to the writer. It returns the LabelRouteWriter itself for chaining.
+// If the pattern has been routed, the writer will replace the previous one.
+func (r *LabelRouteWriter) Route(pattern string, w io.Writer) *LabelRouteWriter {
+	lw := LevelWriterAdapter(w)
+	prefix, ok := strings.CutSuffix(pattern, "*")
+	if !ok {
+		r.exactRoutes[pattern] = lw
+		return r
+	}
+	for i := range r.prefixRoutes {
+		if r.prefixRoutes[i].prefix == prefix {
+			r.prefixRoutes[i].writer = lw
+			return r
+		}
+	}
+	r.prefixRoutes = append(r.prefixRoutes, labelPrefixRoute{prefix: prefix, writer: lw})
+	sort.SliceStable(r.prefixRoutes, func(i, j int) bool {
+		return len(r.prefixRoutes[i].prefix) > len(r.prefixRoutes[j].prefix)
+	})
+	return r
+}
+
+// Write implements the io.Writer interface by writing to the default writer.
+func (r *LabelRouteWriter) Write(bz []byte) (n int, err error) {
+	if r.defaultWriter == nil {
+		return len(bz), nil
+	}
+	return r.defaultWriter.Write(bz)
+}
+
+// WriteLevel implements the LevelWriter interface by writing to the default writer,
+// since there is no label information.
+func (r *LabelRouteWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
+	return r.WriteLabelLevel("", lv, bz)
+}
+
+// WriteLabelLevel implements the LabelLevelWriter interface by writing to the writer routed by the label.
+func (r *LabelRouteWriter) WriteLabelLevel(label string, lv level.Level, bz []byte) (n int, err error) {
+	w := r.match(label)
+	if w == nil {
+		return len(bz), nil
+	}
+	return writeLabelLevel(w, label, lv, bz)
+}
+
+// match finds the writer routed by the label.
+func (r *LabelRouteWriter) match(label string) LevelWriter {
+	if label == "" {
+		return r.defaultWriter
+	}
+	if w, ok := r.exactRoutes[label]; ok {
+		return w
+	}
+	for _, route := range r.prefixRoutes {
+		if strings.HasPrefix(label, route.prefix) {
+			return route.writer
+		}
+		if parent, ok := strings.CutSuffix(route.prefix, "|"); ok && label == parent {
+			return route.writer
+		}
+	}
+	return r.defaultWriter
+}
+
+// Flush flushes all writers that implement the Flush() method.
+// Returns the first error encountered.
+func (r *LabelRouteWriter) Flush() (err error) {
+	for _, w := range r.distinctWriters() {
+		if fw, ok := w.(interface{ Flush() error }); ok {
+			if e := fw.Flush(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// Close closes all writers that implement the io.Closer interface.
+// Returns the first error encountered.
+func (r *LabelRouteWriter) Close() (err error) {
+	for _, w := range r.distinctWriters() {
+		if c, ok := w.(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// distinctWriters returns the writers unwrapped, a writer routed by several patterns is returned only once.
+func (r *LabelRouteWriter) distinctWriters() []io.Writer {
+	writers := make([]LevelWriter, 0, len(r.exactRoutes)+len(r.prefixRoutes)+1)
+	if r.defaultWriter != nil {
+		writers = append(writers, r.defaultWriter)
+	}
+	patterns := make([]string, 0, len(r.exactRoutes))
+	for pattern := range r.exactRoutes {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		writers = append(writers, r.exactRoutes[pattern])
+	}
+	for _, route := range r.prefixRoutes {
+		writers = append(writers, route.writer)
+	}
+	return distinctWriters(writers)
+}
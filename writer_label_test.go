@@ -0,0 +1,109 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelRouteWriter(t *testing.T) {
+	t.Run("RouteByLabel", func(t *testing.T) {
+		audit, db, slow, other := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+		w := NewLabelRouteWriter(other).
+			Route("audit", audit).
+			Route("db|*", db).
+			Route("db|query|slow*", slow)
+
+		for label, expected := range map[string]*bytes.Buffer{
+			"audit":           audit,
+			"audit|login":     other,
+			"db":              db,
+			"db|query":        db,
+			"db|query|slow":   slow,
+			"dbx":             other,
+			"":                other,
+			"scheduler|retry": other,
+		} {
+			expected.Reset()
+			_, err := w.WriteLabelLevel(label, level.Info, []byte(label))
+			require.NoError(t, err)
+			assert.Equal(t, label, expected.String(), label)
+		}
+	})
+
+	t.Run("DiscardWithoutDefaultWriter", func(t *testing.T) {
+		audit := &bytes.Buffer{}
+		w := NewLabelRouteWriter(nil).Route("audit", audit)
+
+		n, err := w.WriteLabelLevel("db", level.Info, []byte("db"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, "", audit.String())
+	})
+
+	t.Run("WorkWithLogger", func(t *testing.T) {
+		audit, other := &bytes.Buffer{}, &bytes.Buffer{}
+		logger := New(
+			AppendsEncoderWriters(TextEnc, NewLabelRouteWriter(other).Route("audit", audit)),
+			WithMetaKeys(),
+			WithLabels("app"),
+		)
+
+		logger.Info().Msg("started").Done()
+		logger.Info().WithLabels("audit").Msg("login").Done()
+		assert.Equal(t, "message=login\n", audit.String())
+		assert.Equal(t, "message=started\n", other.String())
+	})
+
+	t.Run("ThroughMultiLevelWriter", func(t *testing.T) {
+		audit, all := &bytes.Buffer{}, &bytes.Buffer{}
+		logger := New(
+			AppendsEncoderWriters(TextEnc, NewLabelRouteWriter(nil).Route("audit", audit), all),
+			WithMetaKeys(),
+		)
+
+		logger.Info().WithLabels("audit").Msg("login").Done()
+		logger.Info().Msg("started").Done()
+		assert.Equal(t, "message=login\n", audit.String())
+		assert.Equal(t, "message=login\nmessage=started\n", all.String())
+	})
+
+	t.Run("WithConfig", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := config.DefaultLoggerConfig()
+		cfg.EnableConsolePrinting = false
+		cfg.LabelRouteFileConfig.Enable = true
+		cfg.LabelRouteFileConfig.LogFilePath = dir
+		cfg.LabelRouteFileConfig.DefaultLogFileBaseName = "app.log"
+		cfg.LabelRouteFileConfig.Routes = []config.LoggerLabelRouteConfig{
+			{Label: "audit", LogFileBaseName: "audit.log"},
+			{Label: "db|*", LogFileBaseName: "db.log"},
+			{Label: "cache", LogFileBaseName: "db.log"},
+		}
+		cfg.LabelRouteFileConfig.Encoder = "text"
+		logger := New(WithDefault(), WithConfig(cfg), WithMetaKeys())
+		closeLoggerWriters(t, logger)
+
+		logger.Info().Msg("started").Done()
+		logger.Info().WithLabels("audit").Msg("login").Done()
+		logger.Info().WithLabels("db", "query").Msg("select").Done()
+		logger.Info().WithLabels("cache").Msg("miss").Done()
+		require.NoError(t, logger.Flush())
+
+		for file, content := range map[string]string{
+			"app.log":   "message=started\n",
+			"audit.log": "message=login\n",
+			"db.log":    "message=select\nmessage=miss\n",
+		} {
+			bz, err := os.ReadFile(filepath.Join(dir, file))
+			require.NoError(t, err)
+			assert.Equal(t, content, string(bz), file)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package rainbowlog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var (
+	_ LevelWriter = (*ReopenFileWriter)(nil)
+	_ Reopener    = (*ReopenFileWriter)(nil)
+	_ Reopener    = (*BufferedWriter)(nil)
+)
+
+// Reopener is an interface implemented by writers that are able to reopen their underlying files.
+type Reopener interface {
+	Reopen() error
+}
+
+// ReopenFileWriter is a file writer that works with external log rotating tools like logrotate.
+// It reopens the file when Reopen is called, e.g. on SIGHUP by using ReopenOnSignal,
+// and it also detects whether the file has been moved, removed or truncated underneath it
+// when writing, so both "create" and "copytruncate" semantics of logrotate are supported.
+type ReopenFileWriter struct {
+	mu            sync.Mutex
+	file          *os.File
+	size          int64
+	lastCheck     time.Time
+	filePath      string
+	checkInterval time.Duration
+}
+
+// NewReopenFileWriter creates a new ReopenFileWriter.
+//
+//	params:
+//		- filePath: defines the path of the log file.
+//		- checkInterval: defines the minimum interval of checking whether the file has been
+//			moved or truncated when writing. If it is not positive, the checking will be disabled
+//			and the file will only be reopened by calling Reopen.
+func NewReopenFileWriter(filePath string, checkInterval time.Duration) (*ReopenFileWriter, error) {
+	w := &ReopenFileWriter{
+		filePath:      filePath,
+		checkInterval: checkInterval,
+	}
+	if err := w.openFile(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes data to the file.
+// If the file has been moved or removed since last checking, it will be reopened before writing.
+func (w *ReopenFileWriter) Write(bz []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if err = w.tryReopen(); err != nil {
+		return 0, err
+	}
+	n, err = w.file.Write(bz)
+	w.size += int64(n)
+	return n, err
+}
+
+// WriteLevel implements the LevelWriter interface by calling Write and ignoring the level.
+func (w *ReopenFileWriter) WriteLevel(_ level.Level, bz []byte) (n int, err error) {
+	return w.Write(bz)
+}
+
+// Reopen closes the current file and opens the file path again.
+func (w *ReopenFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.reopen()
+}
+
+// Close closes the file.
+func (w *ReopenFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// tryReopen checks whether the file has been moved, removed or truncated if the check interval elapsed.
+// The file will be reopened if it has been moved or removed.
+// If it has been truncated, the writing will continue at the new end of the file.
+func (w *ReopenFileWriter) tryReopen() error {
+	if w.checkInterval <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if now.Sub(w.lastCheck) < w.checkInterval {
+		return nil
+	}
+	w.lastCheck = now
+
+	current, err := w.file.Stat()
+	if err != nil {
+		return w.reopen()
+	}
+	info, err := os.Stat(w.filePath)
+	if err != nil || !os.SameFile(current, info) {
+		// moved or removed
+		return w.reopen()
+	}
+	if current.Size() < w.size {
+		// truncated, the file is opened with O_APPEND, so nothing to do but resetting the size
+		w.size = current.Size()
+	}
+	return nil
+}
+
+// reopen closes the current file and opens the file path again.
+func (w *ReopenFileWriter) reopen() error {
+	old := w.file
+	if err := w.openFile(); err != nil {
+		return err
+	}
+	return old.Close()
+}
+
+// openFile opens the file path for writing.
+func (w *ReopenFileWriter) openFile() error {
+	file, err := os.OpenFile(w.filePath, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	w.lastCheck = time.Now()
+	return nil
+}
+
+// ReopenOnSignal starts a goroutine that calls r.Reopen() whenever one of the signals given is received.
+// If no signal given, syscall.SIGHUP will be used.
+// Errors returned by r.Reopen() will be passed to ErrorHandler.
+// The returned function stops the listening.
+//
+// If a ReopenFileWriter is wrapped by a BufferedWriter, the BufferedWriter should be passed in,
+// so that the buffered data will be written into the reopened file rather than the rotated one.
+func ReopenOnSignal(r Reopener, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, sigs...)
+	go func() {
+		for {
+			select {
+			case <-sigCh:
+				if err := r.Reopen(); err != nil && ErrorHandler != nil {
+					ErrorHandler(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package rainbowlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReopenFileWriter(t *testing.T) {
+	t.Run("Reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "test.log")
+		w, err := NewReopenFileWriter(filePath, 0)
+		require.NoError(t, err)
+		defer w.Close()
+
+		_, err = w.Write([]byte("before"))
+		require.NoError(t, err)
+		require.NoError(t, os.Rename(filePath, filePath+".1"))
+
+		// not reopened yet, still writing into the moved file
+		_, err = w.Write([]byte("|moved"))
+		require.NoError(t, err)
+
+		require.NoError(t, w.Reopen())
+		_, err = w.Write([]byte("after"))
+		require.NoError(t, err)
+
+		rotated, err := os.ReadFile(filePath + ".1")
+		require.NoError(t, err)
+		assert.Equal(t, "before|moved", string(rotated))
+		current, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "after", string(current))
+	})
+
+	t.Run("DetectMoved", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "test.log")
+		w, err := NewReopenFileWriter(filePath, time.Nanosecond)
+		require.NoError(t, err)
+		defer w.Close()
+
+		_, err = w.Write([]byte("before"))
+		require.NoError(t, err)
+		require.NoError(t, os.Rename(filePath, filePath+".1"))
+		time.Sleep(time.Millisecond)
+		_, err = w.Write([]byte("after"))
+		require.NoError(t, err)
+
+		rotated, err := os.ReadFile(filePath + ".1")
+		require.NoError(t, err)
+		assert.Equal(t, "before", string(rotated))
+		current, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "after", string(current))
+	})
+
+	t.Run("DetectTruncated", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "test.log")
+		w, err := NewReopenFileWriter(filePath, time.Nanosecond)
+		require.NoError(t, err)
+		defer w.Close()
+
+		_, err = w.Write([]byte("before"))
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(filePath, 0))
+		time.Sleep(time.Millisecond)
+		_, err = w.Write([]byte("after"))
+		require.NoError(t, err)
+
+		current, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "after", string(current))
+		assert.Equal(t, int64(5), w.size)
+	})
+
+	t.Run("BufferedWriterReopen", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "test.log")
+		w, err := NewReopenFileWriter(filePath, 0)
+		require.NoError(t, err)
+		bw := NewBufferedWriter(w, 64)
+
+		_, err = bw.Write([]byte("buffered"))
+		require.NoError(t, err)
+		require.NoError(t, os.Rename(filePath, filePath+".1"))
+		require.NoError(t, bw.Reopen())
+		require.NoError(t, bw.Close())
+		require.NoError(t, w.Close())
+
+		rotated, err := os.ReadFile(filePath + ".1")
+		require.NoError(t, err)
+		assert.Empty(t, rotated)
+		current, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "buffered", string(current))
+	})
+
+	t.Run("WriteAfterClose", func(t *testing.T) {
+		w, err := NewReopenFileWriter(filepath.Join(t.TempDir(), "test.log"), 0)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		_, err = w.Write([]byte("hello"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+		assert.ErrorIs(t, w.Reopen(), os.ErrClosed)
+	})
+}
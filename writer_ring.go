@@ -0,0 +1,156 @@
+package rainbowlog
+
+import (
+	"io"
+	"sync"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var _ LevelWriter = (*RingBufferWriter)(nil)
+
+// ringRecord is an encoded record retained by RingBufferWriter.
+type ringRecord struct {
+	level level.Level
+	bz    []byte
+}
+
+// RingBufferWriter is a "flight recorder" writer that keeps the most recent records in memory
+// instead of writing them out. Once a record at or above the trigger level is written,
+// or Dump is called, the retained records will be written to the target writer in order,
+// followed by the triggering record.
+type RingBufferWriter struct {
+	mu           sync.Mutex
+	target       LevelWriter
+	triggerLevel level.Level
+	maxRecords   int
+	maxBytes     int
+
+	records []ringRecord // Circular buffer of retained records
+	head    int          // Index of the oldest record
+	count   int          // Number of retained records
+	bytes   int          // Total size of retained records
+}
+
+// NewRingBufferWriter creates a new RingBufferWriter.
+//
+//	params:
+//		- target: defines the writer which the retained records will be dumped to.
+//		- maxRecords: defines the maximum number of records to retain. If it is not positive, no limit.
+//		- maxBytes: defines the maximum total size of records to retain. If it is not positive, no limit.
+//		- triggerLevel: defines the minimum level of records that trigger dumping.
+//			Set it to level.Disabled to dump only by calling Dump.
+//
+// If both maxRecords and maxBytes are not positive, all records will be retained until dumping.
+func NewRingBufferWriter(target io.Writer, maxRecords, maxBytes int, triggerLevel level.Level) *RingBufferWriter {
+	w := &RingBufferWriter{
+		target:       LevelWriterAdapter(target),
+		triggerLevel: triggerLevel,
+		maxRecords:   maxRecords,
+		maxBytes:     maxBytes,
+	}
+	if maxRecords > 0 {
+		w.records = make([]ringRecord, maxRecords)
+	}
+	return w
+}
+
+// Write implements the io.Writer interface by retaining the data as a record without level.
+// Records without level never trigger dumping.
+func (w *RingBufferWriter) Write(bz []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.push(level.None, bz)
+	return len(bz), nil
+}
+
+// WriteLevel implements the LevelWriter interface.
+// If the level is lower than the trigger level, the record will be retained,
+// otherwise all retained records and the record given will be written to the target writer.
+func (w *RingBufferWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// custom levels may be greater than Disabled, so disabled triggering is checked explicitly
+	if lv == level.None || w.triggerLevel == level.Disabled || lv < w.triggerLevel {
+		w.push(lv, bz)
+		return len(bz), nil
+	}
+	if err = w.dump(); err != nil {
+		return 0, err
+	}
+	return w.target.WriteLevel(lv, bz)
+}
+
+// Dump writes all retained records to the target writer and clears them.
+func (w *RingBufferWriter) Dump() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dump()
+}
+
+// Len returns the number of records retained.
+func (w *RingBufferWriter) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// dump writes all retained records to the target writer in order and clears them.
+// The records failed to write will be dropped.
+func (w *RingBufferWriter) dump() (err error) {
+	for w.count > 0 {
+		r := w.records[w.head]
+		w.pop()
+		if r.level == level.None {
+			_, e := w.target.Write(r.bz)
+			if e != nil && err == nil {
+				err = e
+			}
+			continue
+		}
+		if _, e := w.target.WriteLevel(r.level, r.bz); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// push retains a copy of the record, and evicts the oldest ones if limits exceeded.
+func (w *RingBufferWriter) push(lv level.Level, bz []byte) {
+	if w.maxRecords > 0 && w.count >= w.maxRecords {
+		w.pop()
+	}
+	if w.count == len(w.records) {
+		w.grow()
+	}
+	r := &w.records[(w.head+w.count)%len(w.records)]
+	r.level = lv
+	// reuse the memory of the evicted record, since the bytes given may be reused by the caller
+	r.bz = append(r.bz[:0], bz...)
+	w.count++
+	w.bytes += len(bz)
+	for w.maxBytes > 0 && w.bytes > w.maxBytes && w.count > 0 {
+		w.pop()
+	}
+}
+
+// pop evicts the oldest record.
+func (w *RingBufferWriter) pop() {
+	w.bytes -= len(w.records[w.head].bz)
+	w.head = (w.head + 1) % len(w.records)
+	w.count--
+}
+
+// grow doubles the capacity of the circular buffer, keeping the order of records.
+func (w *RingBufferWriter) grow() {
+	newCap := len(w.records) * 2
+	if newCap == 0 {
+		newCap = 16
+	}
+	records := make([]ringRecord, newCap)
+	for i := 0; i < w.count; i++ {
+		records[i] = w.records[(w.head+i)%len(w.records)]
+	}
+	w.records = records
+	w.head = 0
+}
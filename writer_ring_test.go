@@ -0,0 +1,94 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingBufferWriter(t *testing.T) {
+	t.Run("DumpOnTriggerLevel", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewRingBufferWriter(buf, 0, 0, level.Error)
+
+		for _, s := range []string{"debug1\n", "info2\n", "warn3\n"} {
+			_, err := w.WriteLevel(level.Debug, []byte(s))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, "", buf.String())
+		assert.Equal(t, 3, w.Len())
+
+		_, err := w.WriteLevel(level.Error, []byte("error4\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "debug1\ninfo2\nwarn3\nerror4\n", buf.String())
+		assert.Equal(t, 0, w.Len())
+	})
+
+	t.Run("DumpOnlyByCallingDump", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewRingBufferWriter(buf, 0, 0, level.Disabled)
+
+		// custom levels may be greater than Disabled
+		for _, lv := range []level.Level{level.Panic, level.Disabled + 1} {
+			_, err := w.WriteLevel(lv, []byte(lv.String()+"\n"))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, "", buf.String())
+		assert.Equal(t, 2, w.Len())
+
+		require.NoError(t, w.Dump())
+		assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	})
+
+	t.Run("RetainLastRecords", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewRingBufferWriter(buf, 2, 0, level.Error)
+
+		bz := make([]byte, 0, 8)
+		for _, s := range []string{"a", "b", "c"} {
+			// the bytes given are reused, so the writer must keep its own copy
+			bz = append(bz[:0], s...)
+			_, err := w.WriteLevel(level.Info, bz)
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Dump())
+		assert.Equal(t, "bc", buf.String())
+	})
+
+	t.Run("RetainLastBytes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := NewRingBufferWriter(buf, 0, 5, level.Error)
+
+		for _, s := range []string{"aa", "bb", "cc", "dd"} {
+			_, err := w.WriteLevel(level.Info, []byte(s))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, w.Len())
+		_, err := w.WriteLevel(level.Fatal, []byte("!"))
+		require.NoError(t, err)
+		assert.Equal(t, "ccdd!", buf.String())
+	})
+
+	t.Run("WorkWithLogger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		ring := NewRingBufferWriter(buf, 10, 0, level.Error)
+		logger := New(
+			AppendsEncoderWriters(JsonEnc, ring),
+			WithMetaKeys(MetaLevelFieldName),
+		)
+
+		logger.Debug().Msg("step 1").Done()
+		logger.Info().Msg("step 2").Done()
+		assert.Equal(t, "", buf.String())
+
+		logger.Error().Msg("failed").Done()
+		assert.Equal(t, `{"_LEVEL_":"DEBUG","message":"step 1"}
+{"_LEVEL_":"INFO","message":"step 2"}
+{"_LEVEL_":"ERROR","message":"failed"}
+`, buf.String())
+	})
+}
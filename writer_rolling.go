@@ -0,0 +1,587 @@
+package rainbowlog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rambollwong/rainbowcat/writer/filewriter"
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var _ LevelWriter = (*RollingFileWriter)(nil)
+
+// Compression defines the algorithm used to compress rotated log files.
+type Compression string
+
+const (
+	// CompressionNone disables the compression of rotated log files.
+	CompressionNone Compression = ""
+	// CompressionGzip compresses rotated log files with gzip, ".gz" will be appended to the file name.
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd compresses rotated log files with zstd, ".zst" will be appended to the file name.
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression converts a compression string into a Compression.
+// An empty string or "none" means no compression.
+func ParseCompression(compression string) (Compression, error) {
+	switch strings.ToLower(compression) {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return CompressionZstd, nil
+	default:
+		return CompressionNone, errors.New("unsupported compression: " + compression)
+	}
+}
+
+// suffix returns the file name suffix of files compressed by the compression.
+func (c Compression) suffix() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// newWriter wraps w with a compressing writer.
+func (c Compression) newWriter(w io.Writer) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, errors.New("unsupported compression: " + string(c))
+	}
+}
+
+// RollingFileWriter is a file writer that rotates its file by size, by time period or by
+// whichever comes first, and compresses the rotated files in the background.
+//
+// The file being written is always named by the base file name. When rotating,
+// it is renamed with the time of the segment, e.g. rainbow.20240219_19_50_09.log,
+// then compressed to rainbow.20240219_19_50_09.log.gz if compression enabled.
+// If rotating by both size and time period, a sequence number of the segment in the period
+// is also appended to the time, e.g. rainbow.20240219.3.log.
+// Compressed files are counted as backups when removing old files.
+type RollingFileWriter struct {
+	mu           sync.Mutex
+	file         *os.File
+	closed       bool
+	currentSize  int64
+	segmentStart time.Time
+	nextRotate   time.Time
+
+	basePath       string
+	baseFilePrefix string
+	baseFileExt    string
+	maxBackups     int
+	maxAge         time.Duration
+	maxTotalSize   int64
+	fileSizeLimit  int64
+	rollPeriod     filewriter.RollingPeriod
+	compression    Compression
+
+	bMu sync.Mutex     // Backups lock to ensure compressing and removing backups run one at a time
+	wg  sync.WaitGroup // Used to wait for all background compressing to complete
+}
+
+// NewSizeRollingFileWriter creates a new RollingFileWriter rotating by file size.
+//
+// NOTICE: unlike filewriter.NewSizeRollingFileWriter of rainbowcat, backups are named by time
+// instead of rainbow.1.log, rainbow.2.log, etc., and maxBackups of zero keeps no backup.
+//
+//	params:
+//		- basePath: defines the path to save the files.
+//		- baseFileName: defines the base name of the files.
+//		- maxBackups: defines the maximum number of file backups to keep, compressed or not.
+//			Zero keeps no backup, the rotated file is removed at once.
+//			If there is no limit, set the value to a negative value.
+//		- fileSizeLimit: defines the maximum size of each file in bytes.
+//		- compression: defines the algorithm used to compress rotated files.
+func NewSizeRollingFileWriter(
+	basePath, baseFileName string,
+	maxBackups int,
+	fileSizeLimit int64,
+	compression Compression,
+) (*RollingFileWriter, error) {
+	return newRollingFileWriter(basePath, baseFileName, maxBackups, 0, 0, fileSizeLimit, "", compression)
+}
+
+// NewTimeRollingFileWriter creates a new RollingFileWriter rotating by time period.
+//
+// NOTICE: unlike filewriter.NewTimeRollingFileWriter of rainbowcat, maxBackups counts backups
+// instead of periods, zero keeps no backup and a negative value keeps all backups.
+//
+//	params:
+//		- basePath: defines the path to save the files.
+//		- baseFileName: defines the base name of the files.
+//		- maxBackups: defines the maximum number of file backups to keep, compressed or not.
+//			Zero keeps no backup, the rotated file is removed at once.
+//			If there is no limit, set the value to a negative value.
+//		- rollPeriod: specify the time rolling period.
+//		- compression: defines the algorithm used to compress rotated files.
+func NewTimeRollingFileWriter(
+	basePath, baseFileName string,
+	maxBackups int,
+	rollPeriod filewriter.RollingPeriod,
+	compression Compression,
+) (*RollingFileWriter, error) {
+	if _, err := rollingTimeFormat(rollPeriod); err != nil {
+		return nil, err
+	}
+	return newRollingFileWriter(basePath, baseFileName, maxBackups, 0, 0, 0, rollPeriod, compression)
+}
+
+// NewHybridRollingFileWriter creates a new RollingFileWriter rotating by time period or by file size,
+// whichever comes first.
+//
+//	params:
+//		- basePath: defines the path to save the files.
+//		- baseFileName: defines the base name of the files.
+//		- rollPeriod: specify the time rolling period.
+//		- fileSizeLimit: defines the maximum size of each file in bytes.
+//		- maxBackups: defines the maximum number of file backups to keep, compressed or not.
+//			Zero keeps no backup, the rotated file is removed at once.
+//			If there is no limit, set the value to a negative value.
+//		- maxAge: defines the maximum duration to keep a backup since it was rotated.
+//			If there is no limit, set the value to zero.
+//		- maxTotalSize: defines the maximum total size in bytes of all backups.
+//			If there is no limit, set the value to zero.
+//		- compression: defines the algorithm used to compress rotated files.
+func NewHybridRollingFileWriter(
+	basePath, baseFileName string,
+	rollPeriod filewriter.RollingPeriod,
+	fileSizeLimit int64,
+	maxBackups int,
+	maxAge time.Duration,
+	maxTotalSize int64,
+	compression Compression,
+) (*RollingFileWriter, error) {
+	if _, err := rollingTimeFormat(rollPeriod); err != nil {
+		return nil, err
+	}
+	return newRollingFileWriter(
+		basePath, baseFileName, maxBackups, maxAge, maxTotalSize, fileSizeLimit, rollPeriod, compression,
+	)
+}
+
+func newRollingFileWriter(
+	basePath, baseFileName string,
+	maxBackups int,
+	maxAge time.Duration,
+	maxTotalSize int64,
+	fileSizeLimit int64,
+	rollPeriod filewriter.RollingPeriod,
+	compression Compression,
+) (*RollingFileWriter, error) {
+	if compression.suffix() == "" && compression != CompressionNone {
+		return nil, errors.New("unsupported compression: " + string(compression))
+	}
+	if err := os.MkdirAll(basePath, os.ModePerm); err != nil {
+		return nil, err
+	}
+	w := &RollingFileWriter{
+		basePath:      basePath,
+		baseFileExt:   filepath.Ext(baseFileName),
+		maxBackups:    maxBackups,
+		maxAge:        maxAge,
+		maxTotalSize:  maxTotalSize,
+		fileSizeLimit: fileSizeLimit,
+		rollPeriod:    rollPeriod,
+		compression:   compression,
+	}
+	w.baseFilePrefix = strings.TrimSuffix(baseFileName, w.baseFileExt)
+	if err := w.openFile(time.Now()); err != nil {
+		return nil, err
+	}
+	// backups left by a previous run may be uncompressed yet
+	w.compressAndRemoveBackupsAsync()
+	return w, nil
+}
+
+// Write writes data to the file, rotating the file first if necessary.
+func (w *RollingFileWriter) Write(bz []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		// opening the new file failed when rotating, retry it
+		if err = w.openFile(time.Now()); err != nil {
+			return 0, err
+		}
+	}
+	if err = w.tryRotate(int64(len(bz))); err != nil {
+		return 0, err
+	}
+	n, err = w.file.Write(bz)
+	w.currentSize += int64(n)
+	return n, err
+}
+
+// WriteLevel implements the LevelWriter interface by calling Write and ignoring the level.
+func (w *RollingFileWriter) WriteLevel(_ level.Level, bz []byte) (n int, err error) {
+	return w.Write(bz)
+}
+
+// Close closes the file and waits for all background compressing to complete.
+func (w *RollingFileWriter) Close() error {
+	w.mu.Lock()
+	var err error
+	w.closed = true
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+	return err
+}
+
+// tryRotate checks whether the current file exceeds the size limit or the rolling period,
+// and performs rotating if necessary.
+func (w *RollingFileWriter) tryRotate(bytesLength int64) error {
+	now := time.Now()
+	switch {
+	case w.rollPeriod != "" && !now.Before(w.nextRotate):
+	case w.fileSizeLimit > 0 && w.currentSize > 0 && w.currentSize+bytesLength > w.fileSizeLimit:
+	default:
+		return nil
+	}
+	if w.currentSize == 0 {
+		// nothing written in this segment, just move to the next period
+		return w.resetSegment(now)
+	}
+	return w.rotate(now)
+}
+
+// rotate renames the current file to a backup file, opens a new one,
+// and starts compressing and removing backups in the background.
+func (w *RollingFileWriter) rotate(now time.Time) error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if err := os.Rename(w.filePath(), w.backupFilePath()); err != nil {
+		// keep the current file available, rotating will be retried on next writing
+		if file, e := os.OpenFile(w.filePath(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666); e == nil {
+			w.file = file
+		}
+		return err
+	}
+	// the backup is compressed even if opening the new file failed, which will be retried on next writing
+	w.compressAndRemoveBackupsAsync()
+	return w.openFile(now)
+}
+
+// openFile opens the current log file for writing.
+// If the existing file belongs to an expired rolling period, it will be rotated.
+func (w *RollingFileWriter) openFile(now time.Time) error {
+	file, err := os.OpenFile(w.filePath(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.currentSize = info.Size()
+	if w.currentSize == 0 {
+		return w.resetSegment(now)
+	}
+	if err = w.resetSegment(info.ModTime()); err != nil {
+		return err
+	}
+	if w.rollPeriod != "" && !now.Before(w.nextRotate) {
+		return w.rotate(now)
+	}
+	return nil
+}
+
+// resetSegment marks the beginning of a new segment of the current file.
+func (w *RollingFileWriter) resetSegment(start time.Time) error {
+	w.segmentStart = start
+	if w.rollPeriod == "" {
+		return nil
+	}
+	next, err := nextRollingTime(start, w.rollPeriod)
+	if err != nil {
+		return err
+	}
+	w.nextRotate = next
+	return nil
+}
+
+// filePath returns the path of the file being written.
+func (w *RollingFileWriter) filePath() string {
+	return filepath.Join(w.basePath, w.baseFilePrefix+w.baseFileExt)
+}
+
+// backupFilePath returns an unused path for the backup of the current segment.
+func (w *RollingFileWriter) backupFilePath() string {
+	timeFormat := filewriter.TimeFormatSecond
+	if w.rollPeriod != "" {
+		timeFormat, _ = rollingTimeFormat(w.rollPeriod)
+	}
+	stamp := w.segmentStart.Format(timeFormat)
+	name := fmt.Sprintf("%s.%s%s", w.baseFilePrefix, stamp, w.baseFileExt)
+	i := 1
+	if w.rollPeriod != "" && w.fileSizeLimit > 0 {
+		// rotating by both size and time, always name segments with the sequence
+		name = fmt.Sprintf("%s.%s.%d%s", w.baseFilePrefix, stamp, i, w.baseFileExt)
+	}
+	for w.backupExists(name) {
+		i++
+		name = fmt.Sprintf("%s.%s.%d%s", w.baseFilePrefix, stamp, i, w.baseFileExt)
+	}
+	return filepath.Join(w.basePath, name)
+}
+
+// backupExists checks whether the backup file exists, compressed or not.
+func (w *RollingFileWriter) backupExists(name string) bool {
+	for _, c := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		if _, err := os.Stat(filepath.Join(w.basePath, name+c.suffix())); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// compressAndRemoveBackupsAsync starts a goroutine to compress and remove backups.
+func (w *RollingFileWriter) compressAndRemoveBackupsAsync() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.bMu.Lock()
+		defer w.bMu.Unlock()
+		if err := w.compressBackups(); err != nil && ErrorHandler != nil {
+			ErrorHandler(err)
+		}
+		if err := w.removeOldBackups(); err != nil && ErrorHandler != nil {
+			ErrorHandler(err)
+		}
+	}()
+}
+
+// compressBackups compresses all backups that have not been compressed yet.
+func (w *RollingFileWriter) compressBackups() error {
+	if w.compression == CompressionNone {
+		return nil
+	}
+	backups, err := w.listBackups()
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		if isCompressedFile(backup.Name()) {
+			continue
+		}
+		if err = compressFile(filepath.Join(w.basePath, backup.Name()), w.compression); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeOldBackups removes the backups which exceed maxBackups, maxAge or maxTotalSize,
+// the oldest ones first.
+func (w *RollingFileWriter) removeOldBackups() error {
+	if w.maxBackups < 0 && w.maxAge <= 0 && w.maxTotalSize <= 0 {
+		return nil
+	}
+	backups, err := w.listBackups()
+	if err != nil {
+		return err
+	}
+	var (
+		totalSize int64
+		now       = time.Now()
+	)
+	for i, backup := range backups {
+		totalSize += backup.Size()
+		switch {
+		case w.maxBackups >= 0 && i >= w.maxBackups:
+		case w.maxAge > 0 && now.Sub(backup.ModTime()) > w.maxAge:
+		case w.maxTotalSize > 0 && totalSize > w.maxTotalSize:
+		default:
+			continue
+		}
+		if err = os.Remove(filepath.Join(w.basePath, backup.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listBackups returns the backups of the file sorted from the newest to the oldest.
+func (w *RollingFileWriter) listBackups() ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(w.basePath)
+	if err != nil {
+		return nil, errors.New("error while reading dir: " + err.Error())
+	}
+	current := w.baseFilePrefix + w.baseFileExt
+	backups := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == current || !strings.HasPrefix(name, w.baseFilePrefix+".") {
+			continue
+		}
+		stamp, ok := strings.CutSuffix(trimCompressedSuffix(name), w.baseFileExt)
+		if !ok || !isBackupStamp(strings.TrimPrefix(stamp, w.baseFilePrefix+".")) {
+			// other files sharing the prefix, e.g. app.error.log for app.log
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].ModTime().Equal(backups[j].ModTime()) {
+			return backups[i].Name() > backups[j].Name()
+		}
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+	return backups, nil
+}
+
+// isBackupStamp checks whether the string is the time stamp with the optional sequence
+// of a backup file name, which begins with a digit and consists of digits, '_', '-' and '.'.
+func isBackupStamp(s string) bool {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && c != '_' && c != '-' && c != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// compressFile compresses the src file with the compression given and removes the src file.
+// The compressed data is written to a temporary file first, so that an interrupted
+// compressing never leaves a broken backup.
+func compressFile(src string, compression Compression) (err error) {
+	dst := src + compression.suffix()
+	tmp := dst + ".tmp"
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = out.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+	cw, err := compression.newWriter(out)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(cw, in); err != nil {
+		return err
+	}
+	if err = cw.Close(); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	// keep the modification time so that the order of backups is preserved
+	if err = os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	_ = in.Close()
+	return os.Remove(src)
+}
+
+// isCompressedFile checks whether the file name has a compressed file suffix.
+func isCompressedFile(name string) bool {
+	return trimCompressedSuffix(name) != name
+}
+
+// trimCompressedSuffix trims the compressed file suffix of the file name if any.
+func trimCompressedSuffix(name string) string {
+	for _, c := range []Compression{CompressionGzip, CompressionZstd} {
+		if strings.HasSuffix(name, c.suffix()) {
+			return strings.TrimSuffix(name, c.suffix())
+		}
+	}
+	return name
+}
+
+// rollingTimeFormat returns the time format used to name files of the rolling period.
+func rollingTimeFormat(rollPeriod filewriter.RollingPeriod) (string, error) {
+	switch rollPeriod {
+	case filewriter.RollingPeriodYear:
+		return filewriter.TimeFormatYear, nil
+	case filewriter.RollingPeriodMonth:
+		return filewriter.TimeFormatMonth, nil
+	case filewriter.RollingPeriodDay:
+		return filewriter.TimeFormatDay, nil
+	case filewriter.RollingPeriodHour:
+		return filewriter.TimeFormatHour, nil
+	case filewriter.RollingPeriodMinute:
+		return filewriter.TimeFormatMinute, nil
+	case filewriter.RollingPeriodSecond:
+		return filewriter.TimeFormatSecond, nil
+	default:
+		return "", errors.New("unsupported roll period")
+	}
+}
+
+// nextRollingTime returns the beginning of the rolling period next to the one t belongs to.
+func nextRollingTime(t time.Time, rollPeriod filewriter.RollingPeriod) (time.Time, error) {
+	switch rollPeriod {
+	case filewriter.RollingPeriodYear:
+		return time.Date(t.Year()+1, 1, 1, 0, 0, 0, 0, t.Location()), nil
+	case filewriter.RollingPeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, 1, 0), nil
+	case filewriter.RollingPeriodDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1), nil
+	case filewriter.RollingPeriodHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(time.Hour), nil
+	case filewriter.RollingPeriodMinute:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location()).Add(time.Minute), nil
+	case filewriter.RollingPeriodSecond:
+		return time.Date(
+			t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, t.Location(),
+		).Add(time.Second), nil
+	default:
+		return time.Time{}, errors.New("unsupported roll period")
+	}
+}
@@ -0,0 +1,215 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingFileWriter(t *testing.T) {
+	t.Run("SizeRollingWithGzip", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := NewSizeRollingFileWriter(dir, "test.log", 2, 10, CompressionGzip)
+		require.NoError(t, err)
+
+		for _, s := range []string{"0123456789", "abcdefghij", "ABCDEFGHIJ", "9876543210"} {
+			_, err = w.WriteLevel(0, []byte(s))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		current, err := os.ReadFile(filepath.Join(dir, "test.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "9876543210", string(current))
+
+		backups, err := filepath.Glob(filepath.Join(dir, "test.*.log*"))
+		require.NoError(t, err)
+		require.Len(t, backups, 2)
+		contents := make([]string, 0, len(backups))
+		for _, backup := range backups {
+			require.True(t, strings.HasSuffix(backup, ".log.gz"), backup)
+			f, err := os.Open(backup)
+			require.NoError(t, err)
+			r, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			bz, err := io.ReadAll(r)
+			require.NoError(t, err)
+			_ = f.Close()
+			contents = append(contents, string(bz))
+		}
+		assert.ElementsMatch(t, []string{"abcdefghij", "ABCDEFGHIJ"}, contents)
+	})
+
+	t.Run("SizeRollingWithZstd", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := NewSizeRollingFileWriter(dir, "test.log", -1, 10, CompressionZstd)
+		require.NoError(t, err)
+
+		for _, s := range []string{"0123456789", "abcdefghij"} {
+			_, err = w.Write([]byte(s))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		backups, err := filepath.Glob(filepath.Join(dir, "test.*.log.zst"))
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		compressed, err := os.ReadFile(backups[0])
+		require.NoError(t, err)
+		d, err := zstd.NewReader(bytes.NewReader(compressed))
+		require.NoError(t, err)
+		defer d.Close()
+		bz, err := io.ReadAll(d)
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(bz))
+	})
+
+	t.Run("CompressBackupsLeftByPreviousRun", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test.20240219_19_50_09.log"), []byte("old"), 0666))
+
+		w, err := NewSizeRollingFileWriter(dir, "test.log", 5, 10, CompressionGzip)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		_, err = os.Stat(filepath.Join(dir, "test.20240219_19_50_09.log.gz"))
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(dir, "test.20240219_19_50_09.log"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("IgnoreOtherFilesWithSamePrefix", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test.error.log"), []byte("error"), 0666))
+
+		w, err := NewSizeRollingFileWriter(dir, "test.log", 0, 10, CompressionGzip)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		bz, err := os.ReadFile(filepath.Join(dir, "test.error.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "error", string(bz))
+	})
+
+	t.Run("WriteAfterClose", func(t *testing.T) {
+		w, err := NewTimeRollingFileWriter(t.TempDir(), "test.log", 1, "DAY", CompressionNone)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		_, err = w.Write([]byte("hello"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("ReopenAfterOpeningFailed", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := NewSizeRollingFileWriter(dir, "test.log", -1, 10, CompressionNone)
+		require.NoError(t, err)
+		defer func() { _ = w.Close() }()
+
+		// the state left by rotating when the new file failed to open
+		w.mu.Lock()
+		require.NoError(t, w.file.Close())
+		w.file = nil
+		w.mu.Unlock()
+
+		_, err = w.Write([]byte("hello"))
+		require.NoError(t, err)
+		current, err := os.ReadFile(filepath.Join(dir, "test.log"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(current))
+	})
+
+	t.Run("ZeroMaxBackups", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := NewSizeRollingFileWriter(dir, "test.log", 0, 10, CompressionNone)
+		require.NoError(t, err)
+		for _, s := range []string{"0123456789", "abcdefghij"} {
+			_, err = w.Write([]byte(s))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		backups, err := filepath.Glob(filepath.Join(dir, "test.*.log*"))
+		require.NoError(t, err)
+		assert.Empty(t, backups)
+	})
+}
+
+func TestHybridRollingFileWriter(t *testing.T) {
+	t.Run("NameWithTimeAndSequence", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := NewHybridRollingFileWriter(dir, "test.log", "DAY", 10, -1, 0, 0, CompressionNone)
+		require.NoError(t, err)
+
+		for _, s := range []string{"0123456789", "abcdefghij", "ABCDEFGHIJ"} {
+			_, err = w.Write([]byte(s))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		stamp := time.Now().Format("20060102")
+		for i, expected := range []string{"0123456789", "abcdefghij"} {
+			bz, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("test.%s.%d.log", stamp, i+1)))
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(bz))
+		}
+	})
+
+	t.Run("RemoveByTotalSize", func(t *testing.T) {
+		dir := t.TempDir()
+		w, err := NewHybridRollingFileWriter(dir, "test.log", "DAY", 10, -1, 0, 25, CompressionNone)
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			_, err = w.Write([]byte("0123456789"))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		backups, err := filepath.Glob(filepath.Join(dir, "test.*.log"))
+		require.NoError(t, err)
+		assert.Len(t, backups, 2)
+	})
+
+	t.Run("RemoveByAge", func(t *testing.T) {
+		dir := t.TempDir()
+		expired := filepath.Join(dir, "test.20240219.1.log")
+		kept := filepath.Join(dir, "test.20240219.2.log")
+		require.NoError(t, os.WriteFile(expired, []byte("expired"), 0666))
+		require.NoError(t, os.WriteFile(kept, []byte("kept"), 0666))
+		old := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(expired, old, old))
+
+		w, err := NewHybridRollingFileWriter(dir, "test.log", "DAY", 10, -1, time.Hour, 0, CompressionNone)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		_, err = os.Stat(expired)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(kept)
+		assert.NoError(t, err)
+	})
+}
+
+func TestParseCompression(t *testing.T) {
+	for s, expected := range map[string]Compression{
+		"":     CompressionNone,
+		"none": CompressionNone,
+		"GZIP": CompressionGzip,
+		"zstd": CompressionZstd,
+	} {
+		c, err := ParseCompression(s)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, c)
+	}
+	_, err := ParseCompression("lz4")
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package rainbowlog
+
+import (
+	"io"
+	"reflect"
+	"slices"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var _ LevelWriter = (*LevelSplitWriter)(nil)
+
+// LevelSplitWriter dispatches records to different writers by level,
+// e.g. writing records of each level into its own file like app.debug.log, app.info.log and app.error.log.
+// Records of levels without a writer mapped are written to the default writer.
+type LevelSplitWriter struct {
+	writers       map[level.Level]LevelWriter
+	defaultWriter LevelWriter
+}
+
+// NewLevelSplitWriter creates a new LevelSplitWriter.
+//
+//	params:
+//		- writers: map each level to the writer of it, each writer can be a rolling file writer.
+//		- defaultWriter: defines the writer for levels not mapped and for writing without level.
+//			If it is nil, these records will be discarded.
+func NewLevelSplitWriter(writers map[level.Level]io.Writer, defaultWriter io.Writer) *LevelSplitWriter {
+	lsw := &LevelSplitWriter{
+		writers: make(map[level.Level]LevelWriter, len(writers)),
+	}
+	for lv, w := range writers {
+		lsw.writers[lv] = LevelWriterAdapter(w)
+	}
+	if defaultWriter != nil {
+		lsw.defaultWriter = LevelWriterAdapter(defaultWriter)
+	}
+	return lsw
+}
+
+// Write implements the io.Writer interface by writing to the default writer,
+// since there is no level information.
+func (s *LevelSplitWriter) Write(bz []byte) (n int, err error) {
+	if s.defaultWriter == nil {
+		return len(bz), nil
+	}
+	return s.defaultWriter.Write(bz)
+}
+
+// WriteLevel implements the LevelWriter interface by writing to the writer mapped to the level,
+// or the default writer if no writer mapped.
+func (s *LevelSplitWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
+	w, ok := s.writers[lv]
+	if !ok {
+		w = s.defaultWriter
+	}
+	if w == nil {
+		return len(bz), nil
+	}
+	return w.WriteLevel(lv, bz)
+}
+
+// Flush flushes all writers that implement the Flush() method.
+// Returns the first error encountered.
+func (s *LevelSplitWriter) Flush() (err error) {
+	for _, w := range s.distinctWriters() {
+		if fw, ok := w.(interface{ Flush() error }); ok {
+			if e := fw.Flush(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// Close closes all writers that implement the io.Closer interface.
+// Returns the first error encountered.
+func (s *LevelSplitWriter) Close() (err error) {
+	for _, w := range s.distinctWriters() {
+		if c, ok := w.(io.Closer); ok {
+			if e := c.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// distinctWriters returns the writers unwrapped, a writer mapped to several levels is returned only once.
+func (s *LevelSplitWriter) distinctWriters() []io.Writer {
+	writers := make([]LevelWriter, 0, len(s.writers)+1)
+	if s.defaultWriter != nil {
+		writers = append(writers, s.defaultWriter)
+	}
+	levels := make([]level.Level, 0, len(s.writers))
+	for lv := range s.writers {
+		levels = append(levels, lv)
+	}
+	slices.Sort(levels)
+	for _, lv := range levels {
+		writers = append(writers, s.writers[lv])
+	}
+	return distinctWriters(writers)
+}
+
+// distinctWriters unwraps the writers and removes the duplicated ones, keeping the order.
+func distinctWriters(levelWriters []LevelWriter) []io.Writer {
+	writers := make([]io.Writer, 0, len(levelWriters))
+	seen := make(map[io.Writer]struct{}, len(levelWriters))
+	for _, lw := range levelWriters {
+		w := unwrapLevelWriter(lw)
+		if !reflect.TypeOf(w).Comparable() {
+			writers = append(writers, w)
+			continue
+		}
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		seen[w] = struct{}{}
+		writers = append(writers, w)
+	}
+	return writers
+}
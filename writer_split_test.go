@@ -0,0 +1,74 @@
+package rainbowlog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelSplitWriter(t *testing.T) {
+	t.Run("DispatchByLevel", func(t *testing.T) {
+		debug, errs, other := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+		w := NewLevelSplitWriter(map[level.Level]io.Writer{
+			level.Debug: debug,
+			level.Error: errs,
+			level.Fatal: errs,
+		}, other)
+
+		for _, lv := range []level.Level{level.Debug, level.Info, level.Error, level.Fatal} {
+			_, err := w.WriteLevel(lv, []byte(lv.String()+"\n"))
+			require.NoError(t, err)
+		}
+		_, err := w.Write([]byte("nolevel\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "debug\n", debug.String())
+		assert.Equal(t, "error\nfatal\n", errs.String())
+		assert.Equal(t, "info\nnolevel\n", other.String())
+	})
+
+	t.Run("DiscardWithoutDefaultWriter", func(t *testing.T) {
+		errs := &bytes.Buffer{}
+		w := NewLevelSplitWriter(map[level.Level]io.Writer{level.Error: errs}, nil)
+
+		n, err := w.WriteLevel(level.Info, []byte("info"))
+		require.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, "", errs.String())
+	})
+
+	t.Run("WithConfig", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := config.DefaultLoggerConfig()
+		cfg.EnableConsolePrinting = false
+		cfg.LevelSplitFileConfig.Enable = true
+		cfg.LevelSplitFileConfig.LogFilePath = dir
+		cfg.LevelSplitFileConfig.LogFileBaseName = "app.log"
+		cfg.LevelSplitFileConfig.Levels = []string{"INFO", "ERROR"}
+		cfg.LevelSplitFileConfig.Encoder = "text"
+		logger := New(WithDefault(), WithConfig(cfg), WithMetaKeys())
+		closeLoggerWriters(t, logger)
+
+		logger.Debug().Msg("debug").Done()
+		logger.Info().Msg("info").Done()
+		logger.Error().Msg("error").Done()
+		require.NoError(t, logger.Flush())
+
+		for file, content := range map[string]string{
+			"app.log":       "message=debug\n",
+			"app.info.log":  "message=info\n",
+			"app.error.log": "message=error\n",
+		} {
+			bz, err := os.ReadFile(filepath.Join(dir, file))
+			require.NoError(t, err)
+			assert.Equal(t, content, string(bz), file)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+//go:build !windows && !plan9
+
+package rainbowlog
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"github.com/rambollwong/rainbowlog/level"
+)
+
+var _ LevelWriter = (*SyslogWriter)(nil)
+
+func init() {
+	RegisterWriterFactory(WriterTypeSyslog, newSyslogWriter)
+}
+
+// SyslogWriter writes records to syslog with the severity mapped from the level of each record.
+type SyslogWriter struct {
+	w *syslog.Writer
+}
+
+// NewSyslogWriter creates a new SyslogWriter of the syslog.Writer given.
+func NewSyslogWriter(w *syslog.Writer) *SyslogWriter {
+	return &SyslogWriter{w: w}
+}
+
+// Write implements the io.Writer interface by writing with the default priority of the syslog.Writer.
+func (s *SyslogWriter) Write(bz []byte) (n int, err error) {
+	return s.w.Write(bz)
+}
+
+// WriteLevel implements the LevelWriter interface by writing with the severity of the level.
+// Custom levels above Panic are written as notice, custom levels below Trace are written as debug.
+func (s *SyslogWriter) WriteLevel(lv level.Level, bz []byte) (n int, err error) {
+	msg := string(bz)
+	switch {
+	case lv <= level.Debug:
+		err = s.w.Debug(msg)
+	case lv == level.Info:
+		err = s.w.Info(msg)
+	case lv == level.Warn:
+		err = s.w.Warning(msg)
+	case lv == level.Error:
+		err = s.w.Err(msg)
+	case lv == level.Fatal:
+		err = s.w.Crit(msg)
+	case lv == level.Panic:
+		err = s.w.Emerg(msg)
+	default:
+		err = s.w.Notice(msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(bz), nil
+}
+
+// Close closes the connection to syslog.
+func (s *SyslogWriter) Close() error {
+	return s.w.Close()
+}
+
+// syslogWriterOptions are options of the syslog writer.
+// If network is empty, it connects to the local syslog server.
+type syslogWriterOptions struct {
+	Network  string `mapstructure:"network"`
+	Address  string `mapstructure:"address"`
+	Tag      string `mapstructure:"tag"`
+	Facility string `mapstructure:"facility"`
+}
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+func newSyslogWriter(options map[string]any) (LevelWriter, error) {
+	opts := syslogWriterOptions{Facility: "user"}
+	if err := DecodeWriterOptions(options, &opts); err != nil {
+		return nil, err
+	}
+	facility, ok := syslogFacilities[strings.ToLower(opts.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility: %s", opts.Facility)
+	}
+	w, err := syslog.Dial(opts.Network, opts.Address, facility|syslog.LOG_INFO, opts.Tag)
+	if err != nil {
+		return nil, err
+	}
+	return NewSyslogWriter(w), nil
+}
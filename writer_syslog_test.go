@@ -0,0 +1,61 @@
+//go:build !windows && !plan9
+
+package rainbowlog
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rambollwong/rainbowlog/config"
+	"github.com/rambollwong/rainbowlog/level"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogWriter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	w, err := NewWriterByType(WriterTypeSyslog, map[string]any{
+		"network":  "udp",
+		"address":  conn.LocalAddr().String(),
+		"tag":      "app",
+		"facility": "local0",
+	})
+	require.NoError(t, err)
+	defer w.(*SyslogWriter).Close()
+
+	// priority = facility * 8 + severity, local0 is 16
+	for lv, priority := range map[level.Level]string{
+		level.Debug: "<135>",
+		level.Warn:  "<132>",
+		level.Error: "<131>",
+	} {
+		_, err = w.WriteLevel(lv, []byte("hello"))
+		require.NoError(t, err)
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		msg := string(buf[:n])
+		assert.True(t, strings.HasPrefix(msg, priority), msg)
+		assert.True(t, strings.Contains(msg, "app["), msg)
+		assert.True(t, strings.HasSuffix(msg, "hello\n"), msg)
+	}
+
+	_, err = NewWriterByType(WriterTypeSyslog, map[string]any{"facility": "unknown"})
+	assert.Error(t, err)
+}
+
+func TestSyslogWriter_Buffered(t *testing.T) {
+	cfg := config.DefaultLoggerConfig()
+	cfg.Writers = []config.LoggerWriterConfig{{Enable: true, Type: WriterTypeSyslog, Encoder: "txt", UseBufferedWriter: true, WriterBufferSize: "4K"}}
+	_, err := NewFromConfig(cfg)
+	var errs config.ValidationError
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "writers[0].useBufferedWriter", errs[0].Field)
+}
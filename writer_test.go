@@ -5,6 +5,7 @@ import (
 	"io"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/rambollwong/rainbowlog/level"
 	"github.com/stretchr/testify/assert"
@@ -40,9 +41,83 @@ func TestBufferedWriter(t *testing.T) {
 		assert.Equal(t, 5, n)
 
 		// Data should be automatically flushed
+		bw.wg.Wait()
 		assert.Equal(t, "hello", buf.String())
 	})
 
+	t.Run("FlushPeriodically", func(t *testing.T) {
+		buf := &safeBuffer{}
+		bw := NewBufferedWriterWithFlushInterval(buf, 64, 10*time.Millisecond)
+		defer bw.Close()
+
+		_, err := bw.Write([]byte("hello"))
+		assert.NoError(t, err)
+
+		// Data should be flushed by the ticker without filling the buffer
+		assert.Eventually(t, func() bool { return buf.String() == "hello" }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("FlushErrorPropagation", func(t *testing.T) {
+		errorHandler := ErrorHandler
+		defer func() { ErrorHandler = errorHandler }()
+		var handled error
+		ErrorHandler = func(err error) { handled = err }
+
+		w := &switchableWriter{broken: true}
+		bw := NewBufferedWriter(w, 5)
+
+		// The asynchronous flush fails
+		_, err := bw.Write([]byte("hello"))
+		assert.NoError(t, err)
+		bw.wg.Wait()
+		assert.ErrorIs(t, handled, io.ErrClosedPipe)
+
+		// The error is returned by the next Write, which still buffers the data
+		w.broken = false
+		n, err := bw.Write([]byte("a"))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		assert.Equal(t, 1, n)
+
+		// The data failed is flushed again before the newer data
+		assert.NoError(t, bw.Flush())
+		assert.Equal(t, "helloa", w.String())
+		assert.NoError(t, bw.Close())
+	})
+
+	t.Run("RetryInOrder", func(t *testing.T) {
+		errorHandler := ErrorHandler
+		defer func() { ErrorHandler = errorHandler }()
+		ErrorHandler = func(err error) {}
+
+		w := &switchableWriter{broken: true}
+		bw := NewBufferedWriter(w, 5)
+		_, err := bw.Write([]byte("AAAAA"))
+		assert.NoError(t, err)
+		bw.wg.Wait()
+
+		w.broken = false
+		_, err = bw.Write([]byte("BBBBB"))
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+		_, err = bw.Write([]byte("C"))
+		assert.NoError(t, err)
+		assert.NoError(t, bw.Close())
+		assert.Equal(t, "AAAAABBBBBC", w.String())
+	})
+
+	t.Run("RetryAfterShortWrite", func(t *testing.T) {
+		w := &shortWriter{short: 2}
+		bw := NewBufferedWriter(w, 64)
+		_, err := bw.Write([]byte("hello"))
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, bw.Flush(), io.ErrShortWrite)
+		assert.Equal(t, "he", w.String())
+		_, err = bw.Write([]byte(" world"))
+		assert.NoError(t, err)
+		assert.NoError(t, bw.Close())
+		assert.Equal(t, "hello world", w.String())
+	})
+
 	t.Run("WriteAcrossBufferBoundary", func(t *testing.T) {
 		buf := &bytes.Buffer{}
 		bw := NewBufferedWriter(buf, 4)
@@ -247,9 +322,78 @@ func TestMultiLevelWriter(t *testing.T) {
 	})
 }
 
+// safeBuffer is a test helper of thread-safe bytes.Buffer
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(bz []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(bz)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 // errorWriter is a test helper that always returns an error on write
 type errorWriter struct{}
 
 func (w *errorWriter) Write(_ []byte) (int, error) {
 	return 0, io.ErrUnexpectedEOF
 }
+
+// shortWriter is a test helper that writes only the first short bytes and fails once.
+type shortWriter struct {
+	bytes.Buffer
+	short int
+}
+
+func (w *shortWriter) Write(bz []byte) (int, error) {
+	if w.short > 0 && len(bz) > w.short {
+		n, _ := w.Buffer.Write(bz[:w.short])
+		w.short = 0
+		return n, io.ErrShortWrite
+	}
+	return w.Buffer.Write(bz)
+}
+
+// slowWriter is a test helper that simulates the latency of disk I/O.
+type slowWriter struct {
+	latency time.Duration
+}
+
+func (w slowWriter) Write(bz []byte) (int, error) {
+	time.Sleep(w.latency)
+	return len(bz), nil
+}
+
+func BenchmarkBufferedWriterParallel(b *testing.B) {
+	record := []byte(`{"_LEVEL_":"INFO","_TIME_":"2024-01-01 00:00:00.000","message":"hello world"}` + "\n")
+	targets := map[string]io.Writer{
+		"Discard":   io.Discard,
+		"SlowWrite": slowWriter{latency: 50 * time.Microsecond},
+	}
+	writers := map[string]func(w io.Writer) LevelWriter{
+		"BufferedWriter":           func(w io.Writer) LevelWriter { return NewBufferedWriter(w, 4096) },
+		"ConcurrentBufferedWriter": func(w io.Writer) LevelWriter { return NewConcurrentBufferedWriter(w, 4096) },
+	}
+	for writerName, newWriter := range writers {
+		for name, target := range targets {
+			b.Run(writerName+"/"+name, func(b *testing.B) {
+				bw := newWriter(target)
+				b.SetBytes(int64(len(record)))
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						_, _ = bw.Write(record)
+					}
+				})
+				_ = bw.(io.Closer).Close()
+			})
+		}
+	}
+}